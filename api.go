@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+)
+
+// API
+
+// stateMu serializes every API request that reads or mutates the party and registries.
+var stateMu sync.Mutex
+
+// partyMu is held for the whole of every API request, so the active party can't be switched by
+// another request halfway through. GET requests for the party that is already active only read
+// lock it, so pages and reads of that party don't queue up behind one another.
+var partyMu sync.RWMutex
+
+// registerAPIRoutes serves the party routes twice: under /api for the default party, and under
+// /api/campaigns/:campaign/parties/:party for any party in any campaign.
+func registerAPIRoutes(e *echo.Echo) {
+	api := e.Group("/api", scopeParty, journalEvents)
+	registerCampaignRoutes(api)
+	registerMonsterRoutes(api)
+	registerPartyScopedRoutes(api)
+
+	scoped := e.Group("/api/campaigns/:campaign/parties/:party", scopeParty, journalEvents)
+	registerPartyScopedRoutes(scoped)
+
+	registerStreamRoutes(e)
+}
+
+// registerPartyScopedRoutes registers everything that works on a single party. Changes made
+// through these routes are recorded so they can be undone.
+func registerPartyScopedRoutes(api *echo.Group) {
+	registerHistoryRoutes(api)
+	registerEventRoutes(api)
+
+	recorded := api.Group("", recordHistory)
+	registerPartyRoutes(recorded)
+	registerCharacterRoutes(recorded)
+	registerEncounterRoutes(recorded)
+	registerClockRoutes(recorded)
+	registerRetainerRoutes(recorded)
+}
+
+// scopeParty activates the party named by the :campaign and :party path parameters, or the default
+// party when the route has none, for the rest of the request.
+func scopeParty(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if c.Request().Method == http.MethodGet {
+			partyMu.RLock()
+			pr, err := requestParty(c)
+			if err == nil && pr == activeParty {
+				defer partyMu.RUnlock()
+				return next(c)
+			}
+			partyMu.RUnlock()
+		}
+
+		partyMu.Lock()
+		defer partyMu.Unlock()
+		pr, err := requestParty(c)
+		if err != nil {
+			return apiError(c, http.StatusNotFound, err)
+		}
+		stateMu.Lock()
+		ActivateParty(pr)
+		stateMu.Unlock()
+		return next(c)
+	}
+}
+
+// requestParty is the party named by the :campaign and :party path parameters, or the default
+// party when the route has none. The caller holds partyMu.
+func requestParty(c echo.Context) (*PartyRecord, error) {
+	if c.Param("campaign") != "" && c.Param("party") != "" {
+		return partyFromParams(c, "campaign", "party")
+	}
+	return DefaultParty()
+}
+
+// journalEvents writes the events from a successful request to the journal, along with the change
+// it made to the active party, and throws away the events from a failed one. The change is also
+// added to the party's history when the route records history (see recordHistory). A change from
+// any other route clears the history, since the undo patches would no longer fit the party.
+func journalEvents(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var before json.RawMessage
+		stateMu.Lock()
+		pr := activeParty
+		if c.Request().Method != http.MethodGet {
+			before, _ = takeSnapshot(&State)
+		}
+		stateMu.Unlock()
+
+		err := next(c)
+
+		stateMu.Lock()
+		defer stateMu.Unlock()
+		if err != nil || c.Response().Status >= http.StatusBadRequest {
+			DiscardEvents()
+			return err
+		}
+		action := c.Request().Method + " " + c.Request().URL.Path
+		// A request that switched parties, like loading a save, is picked up by the next snapshot
+		if before != nil && activeParty == pr {
+			after, err := takeSnapshot(&State)
+			var change json.RawMessage
+			if err == nil {
+				change, err = diffSnapshots(before, after)
+			}
+			if err != nil {
+				log.Printf("warning: the change made by %s cannot be replayed or undone: %v", action, err)
+			}
+			JournalChange(change, before, action)
+			if change != nil {
+				switch c.Get(historyKey) {
+				case true:
+					if err := RecordHistory(&activeParty.History, action, before, after, change, &State); err != nil {
+						log.Printf("warning: %s was not recorded in history: %v", action, err)
+					}
+				case nil:
+					activeParty.History = History{}
+				}
+			}
+		}
+		events, err := CommitEvents(&State)
+		if err != nil {
+			log.Printf("warning: %s was not journaled: %v", action, err)
+		}
+		publishEvents(events)
+		return nil
+	}
+}
+
+// partyFromParams looks up a campaign and one of its parties by path parameter.
+func partyFromParams(c echo.Context, campaignParam, partyParam string) (*PartyRecord, error) {
+	campaignID, err := intParam(c, campaignParam)
+	if err != nil {
+		return nil, fmt.Errorf("invalid campaign id")
+	}
+	partyID, err := intParam(c, partyParam)
+	if err != nil {
+		return nil, fmt.Errorf("invalid party id")
+	}
+	campaign, err := FindCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	return FindParty(campaign, partyID)
+}
+
+// API helpers
+
+// apiError writes an error as JSON: {"Error": "..."}
+func apiError(c echo.Context, status int, err error) error {
+	return c.JSON(status, map[string]string{"Error": err.Error()})
+}
+
+// intParam reads a numeric path parameter.
+func intParam(c echo.Context, name string) (int, error) {
+	return strconv.Atoi(c.Param(name))
+}
+
+// withCharacterID reads the :id character under the state lock and runs fn with it.
+// fn's result is written as JSON, or its error as a 400.
+func withCharacterID(c echo.Context, fn func(id int) (any, error)) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	result, err := fn(id)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// withCharacter is withCharacterID for handlers that need the character itself.
+func withCharacter(c echo.Context, fn func(ch *Character) (any, error)) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		ch, err := FindChar(&State, id)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ch)
+	})
+}
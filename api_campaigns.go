@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CAMPAIGN API
+
+// Campaign routes are registered under /api with the default party active. Routes for a single
+// party live under /api/campaigns/:campaign/parties/:party (see registerAPIRoutes).
+func registerCampaignRoutes(api *echo.Group) {
+	api.GET("/campaigns", listCampaignsHandler)
+	api.POST("/campaigns", newCampaignHandler)
+	api.POST("/campaigns/:campaign/parties", addPartyHandler)
+	api.POST("/campaigns/:campaign/parties/:party/characters/:id/move", moveCharacterHandler)
+	api.POST("/save", saveHandler)
+	api.POST("/load", loadHandler)
+}
+
+type nameRequest struct {
+	Name string
+}
+
+// moveCharacterRequest names the destination party. CampaignID defaults to the source campaign.
+type moveCharacterRequest struct {
+	CampaignID int
+	PartyID    int
+}
+
+// campaignSummary and partySummary list campaigns without the full contents of every party.
+type campaignSummary struct {
+	ID      int
+	Name    string
+	Parties []partySummary
+}
+
+type partySummary struct {
+	ID         int
+	Name       string
+	Day        int
+	Characters []string
+}
+
+func summarizeCampaign(c *Campaign) campaignSummary {
+	s := campaignSummary{ID: c.ID, Name: c.Name, Parties: []partySummary{}}
+	for _, pr := range c.Parties {
+		ps := partySummary{ID: pr.ID, Name: pr.Name, Day: pr.Party.Day, Characters: []string{}}
+		for _, ch := range pr.Party.Characters {
+			ps.Characters = append(ps.Characters, ch.Name)
+		}
+		s.Parties = append(s.Parties, ps)
+	}
+	return s
+}
+
+func listCampaignsHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	stashActiveParty()
+	summaries := make([]campaignSummary, 0, len(Campaigns))
+	for _, campaign := range Campaigns {
+		summaries = append(summaries, summarizeCampaign(campaign))
+	}
+	return c.JSON(http.StatusOK, summaries)
+}
+
+func newCampaignHandler(c echo.Context) error {
+	var req nameRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Name == "" {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("campaign needs a name"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	campaign := NewCampaign(req.Name)
+	AddParty(campaign, "Party")
+	return c.JSON(http.StatusCreated, summarizeCampaign(campaign))
+}
+
+func addPartyHandler(c echo.Context) error {
+	id, err := intParam(c, "campaign")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid campaign id"))
+	}
+	var req nameRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Name == "" {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("party needs a name"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	campaign, err := FindCampaign(id)
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	pr := AddParty(campaign, req.Name)
+	return c.JSON(http.StatusCreated, partySummary{ID: pr.ID, Name: pr.Name, Characters: []string{}})
+}
+
+func saveHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if err := SaveCampaigns(SavePath); err != nil {
+		return apiError(c, http.StatusInternalServerError, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func loadHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if err := LoadCampaigns(SavePath); err != nil {
+		return apiError(c, http.StatusInternalServerError, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func moveCharacterHandler(c echo.Context) error {
+	charID, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+	var req moveCharacterRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	from, err := partyFromParams(c, "campaign", "party")
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	campaignID := req.CampaignID
+	if campaignID == 0 {
+		campaignID, _ = intParam(c, "campaign")
+	}
+	campaign, err := FindCampaign(campaignID)
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	to, err := FindParty(campaign, req.PartyID)
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	moved, err := MoveCharacterToParty(charID, from, to)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, moved)
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CHARACTER API
+
+func registerCharacterRoutes(api *echo.Group) {
+	api.POST("/characters", addCharacterHandler)
+	api.PATCH("/characters/:id", patchCharacterHandler)
+	api.DELETE("/characters/:id", deleteCharacterHandler)
+	api.POST("/characters/:id/equip", equipHandler)
+	api.POST("/characters/:id/unequip", unequipHandler)
+	api.POST("/characters/:id/drop", dropAllHandler)
+	api.POST("/characters/:id/spells/memorize", memorizeSpellHandler)
+	api.POST("/characters/:id/spells/cast", castSpellHandler)
+	api.POST("/characters/:id/spells/uncast", uncastSpellHandler)
+	api.POST("/characters/:id/spells/learn", learnSpellHandler)
+	api.POST("/characters/:id/spellbooks", bindSpellbookHandler)
+	api.POST("/characters/:id/items/:itemID/use", useItemHandler)
+	api.POST("/items/:id/move", moveItemHandler)
+	api.GET("/characters/:id/export", exportCharacterHandler)
+	api.POST("/characters/import", importCharacterHandler)
+	api.POST("/characters/spreadsheet", importRosterHandler)
+}
+
+type addCharacterRequest struct {
+	Name string
+}
+
+type equipRequest struct {
+	ItemID int
+}
+
+// unequipRequest names the slot to clear: "armor" or "shield".
+type unequipRequest struct {
+	Slot string
+}
+
+type dropAllRequest struct {
+	Location ItemLocation
+}
+
+type spellRequest struct {
+	SpellID  int
+	Reversed bool
+}
+
+// learnSpellRequest copies a spell from a scroll or spellbook the character carries. SpellID is
+// only needed for a spellbook; a scroll holds a single spell.
+type learnSpellRequest struct {
+	ItemID  int
+	SpellID int
+}
+
+// bindSpellbookRequest names the book a character's known spells are written into.
+type bindSpellbookRequest struct {
+	Name string
+}
+
+// useItemResult is a limited-use item after a use, and the spell it released, if any.
+type useItemResult struct {
+	Item  *LimitedUseItem
+	Spell *Spell
+}
+
+// rosterRequest is a CSV or TSV spreadsheet of characters. Unless Commit is set the import is
+// only previewed.
+type rosterRequest struct {
+	Data      string
+	Delimiter string
+	Columns   map[string]RosterField
+	Commit    bool
+}
+
+// moveItemRequest moves an item to a character (CharacterID) or to a location bucket.
+type moveItemRequest struct {
+	Location    ItemLocation
+	CharacterID int
+}
+
+func addCharacterHandler(c echo.Context) error {
+	var req addCharacterRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if err := ValidateCharacterPatch(CharacterPatch{Name: &req.Name}); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return c.JSON(http.StatusCreated, AddCharacter(&State, req.Name))
+}
+
+func patchCharacterHandler(c echo.Context) error {
+	var patch CharacterPatch
+	if err := c.Bind(&patch); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if err := ValidateCharacterPatch(patch); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		ApplyCharacterPatch(ch, patch)
+		return ch, nil
+	})
+}
+
+func deleteCharacterHandler(c echo.Context) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		return State, DeleteCharacter(id, &State)
+	})
+}
+
+func equipHandler(c echo.Context) error {
+	var req equipRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		it, err := FindItemByID(req.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		switch it.Type {
+		case ItemArmor:
+			err = EquipArmor(ch.ID, req.ItemID, &State)
+		case ItemShield:
+			err = EquipShield(ch.ID, req.ItemID, &State)
+		default:
+			err = fmt.Errorf("%s cannot be equipped", it.Name)
+		}
+		return ch, err
+	})
+}
+
+func unequipHandler(c echo.Context) error {
+	var req unequipRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		switch req.Slot {
+		case "armor":
+			return ch, UnequipArmor(ch.ID, &State)
+		case "shield":
+			return ch, UnequipShield(ch.ID, &State)
+		default:
+			return nil, fmt.Errorf("invalid slot: %q", req.Slot)
+		}
+	})
+}
+
+func dropAllHandler(c echo.Context) error {
+	var req dropAllRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Location == "" {
+		req.Location = LocationParty
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		return ch, MoveAllFromCharacter(ch.ID, req.Location, &State)
+	})
+}
+
+func memorizeSpellHandler(c echo.Context) error {
+	var req spellRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		return ch, MemorizeSpellForm(ch, req.SpellID, req.Reversed)
+	})
+}
+
+func castSpellHandler(c echo.Context) error {
+	var req spellRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		return ch, CastMemorizedSpellForm(ch, req.SpellID, req.Reversed)
+	})
+}
+
+func uncastSpellHandler(c echo.Context) error {
+	var req spellRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		return ch, UncastMemorizedSpell(ch, req.SpellID)
+	})
+}
+
+// learnSpellHandler copies a spell into the character's known spells from a scroll or spellbook.
+func learnSpellHandler(c echo.Context) error {
+	var req learnSpellRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		it, err := FindItemByID(req.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		switch it.Type {
+		case ItemLimitedUse:
+			return LearnSpellFromScroll(id, req.ItemID, &State)
+		case ItemSpellbook:
+			return LearnSpellFromSpellbook(id, req.ItemID, req.SpellID, &State)
+		default:
+			return nil, fmt.Errorf("%s has no spells to learn", it.Name)
+		}
+	})
+}
+
+// bindSpellbookHandler writes the character's known spells into a new spellbook they carry, so
+// they can memorize them when a spellbook is required.
+func bindSpellbookHandler(c echo.Context) error {
+	var req bindSpellbookRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacter(c, func(ch *Character) (any, error) {
+		name := req.Name
+		if name == "" {
+			name = ch.Name + "'s spellbook"
+		}
+		return BindSpellbook(ch.ID, name, &State)
+	})
+}
+
+// useItemHandler spends a charge of a limited-use item the character carries.
+func useItemHandler(c echo.Context) error {
+	itemID, err := intParam(c, "itemID")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid item id"))
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		spell, err := UseLimitedUseItem(id, itemID, &State)
+		if err != nil {
+			return nil, err
+		}
+		return useItemResult{LimitedUseItemsByID[itemID], spell}, nil
+	})
+}
+
+func moveItemHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid item id"))
+	}
+	var req moveItemRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if req.CharacterID != 0 || req.Location == LocationCharacter {
+		err = MoveItemToCharacter(id, req.CharacterID, &State)
+	} else {
+		err = moveItemToLocation(id, req.Location)
+	}
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	it, _ := FindItemByID(id)
+	return c.JSON(http.StatusOK, it)
+}
+
+// moveItemToLocation moves an item into one of the party's buckets.
+func moveItemToLocation(id int, loc ItemLocation) error {
+	switch loc {
+	case LocationParty, LocationStorage, LocationLimbo, LocationNone:
+		return MoveItemToBucket(id, loc, &State)
+	default:
+		return fmt.Errorf("invalid location: %q", loc)
+	}
+}
+
+// exportCharacterHandler returns the character as a document that can be imported into any party.
+func exportCharacterHandler(c echo.Context) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		doc, err := ExportCharacter(id, &State)
+		if err != nil {
+			return nil, err
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"character-%d.json\"", id))
+		return doc, nil
+	})
+}
+
+// importCharacterHandler adds an exported character to the party the route names.
+func importCharacterHandler(c echo.Context) error {
+	var doc CharacterDocument
+	if err := c.Bind(&doc); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	ch, err := ImportCharacter(doc, &State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusCreated, ch)
+}
+
+// importRosterHandler reports what each row of a spreadsheet would become, and adds the accepted
+// rows to the party when the request commits.
+func importRosterHandler(c echo.Context) error {
+	var req rosterRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	report, err := ImportRoster(req.Data, RosterOptions{req.Delimiter, req.Columns}, req.Commit, &State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if report.Committed && report.Accepted > 0 {
+		return c.JSON(http.StatusCreated, report)
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CLOCK API
+
+func registerClockRoutes(api *echo.Group) {
+	api.GET("/clock", getClockHandler)
+	api.POST("/clock/advance", advanceClockHandler)
+	api.POST("/clock/rest", restTurnHandler)
+	api.POST("/characters/:id/light", lightHandler)
+	api.POST("/characters/:id/douse", douseHandler)
+}
+
+// advanceClockRequest defaults to a single turn.
+type advanceClockRequest struct {
+	Amount int
+	Unit   DurationUnit
+}
+
+type lightRequest struct {
+	ItemID int
+}
+
+func getClockHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return c.JSON(http.StatusOK, ClockStatus(&State))
+}
+
+func advanceClockHandler(c echo.Context) error {
+	var req advanceClockRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Amount == 0 {
+		req.Amount = 1
+	}
+	if req.Unit == "" {
+		req.Unit = DurationTurns
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	report, err := AdvanceClock(&State, req.Amount, req.Unit)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func restTurnHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	report, err := RestTurn(&State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func lightHandler(c echo.Context) error {
+	return withLight(c, LightSource)
+}
+
+func douseHandler(c echo.Context) error {
+	return withLight(c, DouseLight)
+}
+
+// withLight lights or douses the light source named in the request body and returns it.
+func withLight(c echo.Context, fn func(charID, itemID int, p *Party) error) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+	var req lightRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if err := fn(id, req.ItemID, &State); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, LimitedUseItemsByID[req.ItemID])
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ENCOUNTER API
+
+func registerEncounterRoutes(api *echo.Group) {
+	api.GET("/encounters", listEncountersHandler)
+	api.POST("/encounters", startEncounterHandler)
+	api.GET("/encounters/:id", getEncounterHandler)
+	api.POST("/encounters/:id/combatants", addCombatantHandler)
+	api.POST("/encounters/:id/monsters", spawnMonstersHandler)
+	api.POST("/encounters/:id/initiative", rollInitiativeHandler)
+	api.POST("/encounters/:id/actions", declareActionHandler)
+	api.POST("/encounters/:id/advance", advanceTurnHandler)
+	api.POST("/encounters/:id/attack", attackHandler)
+	api.POST("/encounters/:id/reaction", reactionHandler)
+	api.POST("/encounters/:id/morale", moraleHandler)
+	api.POST("/encounters/:id/damage", damageHandler)
+	api.POST("/encounters/:id/heal", healHandler)
+	api.POST("/encounters/:id/end", endEncounterHandler)
+}
+
+type startEncounterRequest struct {
+	Name       string
+	Initiative InitiativeMode
+}
+
+// addCombatantRequest adds either a party member (CharacterID) or a named monster.
+// ArmorClass defaults to 9 (unarmored) when omitted.
+type addCombatantRequest struct {
+	CharacterID int
+	Name        string
+	HitPoints   int
+	ArmorClass  *int
+}
+
+type spawnMonstersRequest struct {
+	MonsterID int
+	Count     int
+}
+
+type declareActionRequest struct {
+	CombatantID int
+	Action      string
+}
+
+type reactionRequest struct {
+	CharacterID int
+}
+
+type moraleRequest struct {
+	Side CombatSide
+}
+
+type hitPointsRequest struct {
+	CombatantID int
+	Amount      int
+}
+
+func listEncountersHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	encounters := make([]*Encounter, 0, len(EncountersByID))
+	for id := 1; id < nextEncounterID; id++ {
+		if enc, ok := EncountersByID[id]; ok {
+			encounters = append(encounters, enc)
+		}
+	}
+	return c.JSON(http.StatusOK, encounters)
+}
+
+func startEncounterHandler(c echo.Context) error {
+	var req startEncounterRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Initiative == "" {
+		req.Initiative = InitiativeSide
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	enc, err := StartEncounter(req.Name, req.Initiative, &State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusCreated, enc)
+}
+
+func getEncounterHandler(c echo.Context) error {
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, nil
+	})
+}
+
+func addCombatantHandler(c echo.Context) error {
+	var req addCombatantRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		if req.CharacterID != 0 {
+			return AddCharacterToEncounter(enc, req.CharacterID, &State)
+		}
+		ac := 9
+		if req.ArmorClass != nil {
+			ac = *req.ArmorClass
+		}
+		return AddMonsterToEncounter(enc, req.Name, req.HitPoints, ac)
+	})
+}
+
+func spawnMonstersHandler(c echo.Context) error {
+	var req spawnMonstersRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return SpawnMonsters(enc, req.MonsterID, req.Count)
+	})
+}
+
+func rollInitiativeHandler(c echo.Context) error {
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, RollInitiative(enc, &State)
+	})
+}
+
+func declareActionHandler(c echo.Context) error {
+	var req declareActionRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, DeclareAction(enc, req.CombatantID, req.Action)
+	})
+}
+
+func advanceTurnHandler(c echo.Context) error {
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, AdvanceTurn(enc, &State)
+	})
+}
+
+func attackHandler(c echo.Context) error {
+	var req Attack
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return ResolveAttack(enc, req, &State)
+	})
+}
+
+func reactionHandler(c echo.Context) error {
+	var req reactionRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return RollReaction(enc, req.CharacterID, &State)
+	})
+}
+
+func moraleHandler(c echo.Context) error {
+	var req moraleRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Side == "" {
+		req.Side = SideMonsters
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return CheckMorale(enc, req.Side, "referee")
+	})
+}
+
+func damageHandler(c echo.Context) error {
+	var req hitPointsRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, ApplyDamage(enc, req.CombatantID, req.Amount, &State)
+	})
+}
+
+func healHandler(c echo.Context) error {
+	var req hitPointsRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return enc, ApplyHealing(enc, req.CombatantID, req.Amount, &State)
+	})
+}
+
+func endEncounterHandler(c echo.Context) error {
+	return withEncounter(c, func(enc *Encounter) (any, error) {
+		return EndEncounter(enc, &State)
+	})
+}
+
+// withEncounter looks up the :id encounter under the state lock and runs fn against it.
+// fn's result is written as JSON, or its error as a 400.
+func withEncounter(c echo.Context, fn func(enc *Encounter) (any, error)) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid encounter id"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	enc, err := FindEncounter(id)
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	result, err := fn(enc)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
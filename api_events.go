@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EVENTS API
+
+func registerEventRoutes(api *echo.Group) {
+	api.GET("/events", listEventsHandler)
+	api.POST("/sessions", startSessionHandler)
+	api.GET("/replay", replayHandler)
+	api.POST("/branch", branchHandler)
+}
+
+// listEventsHandler queries the active party's journal. Optional query parameters narrow it down:
+// character, item, session, type, and after (only events with a higher Seq).
+func listEventsHandler(c echo.Context) error {
+	var filter EventFilter
+	ints := map[string]*int{
+		"character": &filter.CharacterID,
+		"item":      &filter.ItemID,
+		"session":   &filter.Session,
+		"after":     &filter.AfterSeq,
+	}
+	for name, dst := range ints {
+		v := c.QueryParam(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+		}
+		*dst = n
+	}
+	filter.Type = EventType(c.QueryParam("type"))
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if campaign := campaignOf(activeParty); campaign != nil {
+		filter.CampaignID = campaign.ID
+	}
+	filter.PartyID = activeParty.ID
+	events, err := ReadJournal(filter)
+	if err != nil {
+		return apiError(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, events)
+}
+
+// replayRequest reads the query parameters shared by the replay routes: until (the last event to
+// replay, all of them by default) and from ("start" to replay from the first snapshot).
+func replayRequest(c echo.Context) (untilSeq int, fromStart bool, err error) {
+	if v := c.QueryParam("until"); v != "" {
+		if untilSeq, err = strconv.Atoi(v); err != nil {
+			return 0, false, fmt.Errorf("invalid until")
+		}
+	}
+	return untilSeq, c.QueryParam("from") == "start", nil
+}
+
+// replayActiveParty rebuilds the active party from its journal.
+func replayActiveParty(untilSeq int, fromStart bool) (partySnapshot, ReplayReport, error) {
+	campaign := campaignOf(activeParty)
+	if campaign == nil {
+		return partySnapshot{}, ReplayReport{}, fmt.Errorf("no active campaign")
+	}
+	return ReplayParty(campaign.ID, activeParty.ID, untilSeq, fromStart)
+}
+
+// replayHandler shows the party as the journal has it at a given event, without changing anything.
+func replayHandler(c echo.Context) error {
+	untilSeq, fromStart, err := replayRequest(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	snap, report, err := replayActiveParty(untilSeq, fromStart)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"Report": report, "Party": snap.Party, "Registry": snap.Registry})
+}
+
+// branchHandler copies the party as the journal has it at a given event into a new party in the
+// same campaign. The body may name the new party.
+func branchHandler(c echo.Context) error {
+	untilSeq, fromStart, err := replayRequest(c)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	var req nameRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	snap, report, err := replayActiveParty(untilSeq, fromStart)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("%s (from event %d)", activeParty.Name, report.UntilSeq)
+	}
+	pr := BranchParty(campaignOf(activeParty), req.Name, snap)
+	return c.JSON(http.StatusCreated, map[string]any{"Report": report, "PartyID": pr.ID, "Name": pr.Name})
+}
+
+func startSessionHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	session, err := StartSession()
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, map[string]int{"Session": session})
+}
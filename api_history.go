@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HISTORY API
+
+func registerHistoryRoutes(api *echo.Group) {
+	api.GET("/history", getHistoryHandler)
+	api.POST("/history/undo", undoHandler)
+	api.POST("/history/redo", redoHandler)
+}
+
+// historySummary lists what can be undone and redone, most recent first, without the snapshots.
+type historySummary struct {
+	Undo []string
+	Redo []string
+}
+
+func getHistoryHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	h := activeParty.History
+	s := historySummary{Undo: []string{}, Redo: []string{}}
+	for i := len(h.Undo) - 1; i >= 0; i-- {
+		s.Undo = append(s.Undo, h.Undo[i].Action)
+	}
+	for i := len(h.Redo) - 1; i >= 0; i-- {
+		s.Redo = append(s.Redo, h.Redo[i].Action)
+	}
+	return c.JSON(http.StatusOK, s)
+}
+
+func undoHandler(c echo.Context) error {
+	return withHistory(c, Undo)
+}
+
+func redoHandler(c echo.Context) error {
+	return withHistory(c, Redo)
+}
+
+// withHistory undoes or redoes a change to the active party and returns the party as it is now.
+func withHistory(c echo.Context, fn func(h *History, p *Party) (HistoryEntry, error)) error {
+	// The change moves between the stacks rather than being recorded
+	c.Set(historyKey, false)
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	entry, err := fn(&activeParty.History, &State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"Action": entry.Action, "Party": State})
+}
+
+// historyKey marks a request whose change goes into the party's history (true), or one that keeps
+// the history itself (false).
+const historyKey = "history"
+
+// recordHistory makes every successful request that changes the active party undoable. journalEvents
+// records the change, from the same snapshots it journals it with.
+func recordHistory(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Set(historyKey, true)
+		return next(c)
+	}
+}
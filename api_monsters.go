@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MONSTER API
+
+func registerMonsterRoutes(api *echo.Group) {
+	api.GET("/monsters", listMonstersHandler)
+	api.GET("/monsters/:id", getMonsterHandler)
+}
+
+func listMonstersHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	monsters := make([]*Monster, 0, len(MonstersByID))
+	for _, m := range bxMonsters {
+		if registered, ok := MonstersByID[m.ID]; ok {
+			monsters = append(monsters, registered)
+		}
+	}
+	return c.JSON(http.StatusOK, monsters)
+}
+
+func getMonsterHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid monster id"))
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	m, ok := MonstersByID[id]
+	if !ok {
+		return apiError(c, http.StatusNotFound, fmt.Errorf("monster %d not found", id))
+	}
+	return c.JSON(http.StatusOK, m)
+}
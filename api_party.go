@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PARTY API
+
+func registerPartyRoutes(api *echo.Group) {
+	api.GET("/party", getPartyHandler)
+	api.POST("/party/rest", restPartyHandler)
+	api.POST("/party/experience", awardExperienceHandler)
+	api.POST("/party/treasure", divideTreasureHandler)
+	api.POST("/characters/:id/status", setCharacterStatusHandler)
+	api.GET("/characters/:id/stats", characterStatsHandler)
+	api.POST("/characters/:id/effects", addEffectHandler)
+	api.DELETE("/characters/:id/effects/:effectID", removeEffectHandler)
+}
+
+type restRequest struct {
+	Kind RestKind
+	Days int
+}
+
+type experienceRequest struct {
+	Experience int
+}
+
+// treasureRequest assigns items by character ID: {"Items": {"3": [12, 14]}}
+type treasureRequest struct {
+	Gold  int
+	Items map[int][]int
+}
+
+type statusRequest struct {
+	Status CharacterStatus
+}
+
+// characterStats are a character's derived combat numbers, with equipment and effects applied.
+type characterStats struct {
+	ArmorClass   int
+	THAC0        int
+	SavingThrows SavingThrows
+	Effects      []ActiveEffect
+}
+
+func getPartyHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	return c.JSON(http.StatusOK, State)
+}
+
+func restPartyHandler(c echo.Context) error {
+	var req restRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Kind == "" {
+		req.Kind = RestNight
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	results, err := RestParty(&State, req.Kind, req.Days)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, results)
+}
+
+func awardExperienceHandler(c echo.Context) error {
+	var req experienceRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	awards, err := AwardExperience(&State, req.Experience)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, awards)
+}
+
+func divideTreasureHandler(c echo.Context) error {
+	var req treasureRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	shares, err := DivideTreasure(&State, req.Gold, req.Items)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusOK, shares)
+}
+
+func setCharacterStatusHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+	var req statusRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if err := SetCharacterStatus(id, req.Status, &State); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	ch, _ := FindChar(&State, id)
+	return c.JSON(http.StatusOK, ch)
+}
+
+func characterStatsHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	ch, err := FindChar(&State, id)
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+	return c.JSON(http.StatusOK, characterStats{
+		ArmorClass:   CharacterArmorClass(ch),
+		THAC0:        characterTHAC0(ch.Class, ch.Level),
+		SavingThrows: CharacterSavingThrows(ch),
+		Effects:      ch.Effects,
+	})
+}
+
+func addEffectHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+	var req ActiveEffect
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	if req.Unit == "" {
+		req.Unit = DurationRounds
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	effect, err := AddEffect(id, req, &State)
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.JSON(http.StatusCreated, effect)
+}
+
+func removeEffectHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character id"))
+	}
+	effectID, err := intParam(c, "effectID")
+	if err != nil {
+		return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid effect id"))
+	}
+
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	if err := RemoveEffect(id, effectID, &State); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
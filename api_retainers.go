@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RETAINER API
+
+func registerRetainerRoutes(api *echo.Group) {
+	api.GET("/characters/:id/retainers", listRetainersHandler)
+	api.POST("/characters/:id/retainers", hireRetainerHandler)
+	api.POST("/characters/:id/wages", payWagesHandler)
+	api.PUT("/retainers/:id", setRetainerTermsHandler)
+	api.DELETE("/retainers/:id", dismissRetainerHandler)
+	api.POST("/retainers/:id/loyalty", checkLoyaltyHandler)
+	api.POST("/retainers/:id/loyalty/adjust", adjustLoyaltyHandler)
+}
+
+type hireRetainerRequest struct {
+	Name string
+	Wage int
+}
+
+// retainerTermsRequest sets a retainer's wage. XPShare keeps its current value when omitted.
+type retainerTermsRequest struct {
+	Wage    int
+	XPShare *int
+}
+
+type loyaltyRequest struct {
+	Change int // adjustments only
+	Reason string
+}
+
+type wagesResult struct {
+	Paid int
+	Gold int
+}
+
+func listRetainersHandler(c echo.Context) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		if _, err := FindChar(&State, id); err != nil {
+			return nil, err
+		}
+		return Retainers(id, &State), nil
+	})
+}
+
+func hireRetainerHandler(c echo.Context) error {
+	var req hireRetainerRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		return HireRetainer(id, req.Name, req.Wage, &State)
+	})
+}
+
+func payWagesHandler(c echo.Context) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		paid, err := PayWages(id, &State)
+		if err != nil {
+			return nil, err
+		}
+		ch, _ := FindChar(&State, id)
+		return wagesResult{Paid: paid, Gold: ch.Gold}, nil
+	})
+}
+
+func setRetainerTermsHandler(c echo.Context) error {
+	var req retainerTermsRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		ch, err := findRetainer(id, &State)
+		if err != nil {
+			return nil, err
+		}
+		xpShare := ch.Retainer.XPShare
+		if req.XPShare != nil {
+			xpShare = *req.XPShare
+		}
+		if err := SetRetainerTerms(id, req.Wage, xpShare, &State); err != nil {
+			return nil, err
+		}
+		return FindChar(&State, id)
+	})
+}
+
+func dismissRetainerHandler(c echo.Context) error {
+	return withCharacterID(c, func(id int) (any, error) {
+		if err := DismissRetainer(id, &State); err != nil {
+			return nil, err
+		}
+		return State, nil
+	})
+}
+
+func checkLoyaltyHandler(c echo.Context) error {
+	var req loyaltyRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		return CheckLoyalty(id, req.Reason, &State)
+	})
+}
+
+func adjustLoyaltyHandler(c echo.Context) error {
+	var req loyaltyRequest
+	if err := c.Bind(&req); err != nil {
+		return apiError(c, http.StatusBadRequest, err)
+	}
+	return withCharacterID(c, func(id int) (any, error) {
+		return AdjustLoyalty(id, req.Change, req.Reason, &State)
+	})
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EVENT STREAM
+
+// The event stream pushes journal events to clients as Server-Sent Events, so everyone watching a
+// party sees changes as they happen. It is registered outside scopeParty, since a stream stays open
+// for as long as the client watches and must not hold the party lock.
+func registerStreamRoutes(e *echo.Echo) {
+	e.GET("/api/events/stream", streamEventsHandler)
+	e.GET("/api/campaigns/:campaign/parties/:party/events/stream", streamEventsHandler)
+}
+
+// streamKeepAlive is how often an idle stream is sent a comment, so proxies don't close it.
+var streamKeepAlive = 15 * time.Second
+
+// subscribers are the open streams, each with the events it wants.
+var (
+	subscribersMu sync.Mutex
+	subscribers   = map[chan Event]EventFilter{}
+)
+
+func subscribe(filter EventFilter) chan Event {
+	ch := make(chan Event, 64)
+	subscribersMu.Lock()
+	subscribers[ch] = filter
+	subscribersMu.Unlock()
+	return ch
+}
+
+func unsubscribe(ch chan Event) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	if _, ok := subscribers[ch]; ok {
+		delete(subscribers, ch)
+		close(ch)
+	}
+}
+
+// publishEvents sends committed events to every stream that wants them. A client too slow to keep
+// up is disconnected; it can reconnect and catch up from the last event it saw.
+func publishEvents(events []Event) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	for ch, filter := range subscribers {
+		for _, e := range events {
+			if !filter.matches(e) {
+				continue
+			}
+			select {
+			case ch <- e:
+			default:
+				delete(subscribers, ch)
+				close(ch)
+			}
+			if _, ok := subscribers[ch]; !ok {
+				break
+			}
+		}
+	}
+}
+
+// streamEventsHandler streams the party's events. The character query parameter narrows the stream
+// to one character. A client reconnecting with after, or the Last-Event-ID header, first gets every
+// event it missed.
+// Streamed events only say what happened. Snapshots are left out, and so is the change each event
+// carries in the journal; a client that wants the party as it is now asks for it.
+func streamEventsHandler(c echo.Context) error {
+	filter := EventFilter{}
+	if v := c.QueryParam("character"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid character"))
+		}
+		filter.CharacterID = id
+	}
+	after := c.QueryParam("after")
+	if after == "" {
+		after = c.Request().Header.Get("Last-Event-ID")
+	}
+	catchUp := after != ""
+	if catchUp {
+		seq, err := strconv.Atoi(after)
+		if err != nil {
+			return apiError(c, http.StatusBadRequest, fmt.Errorf("invalid after"))
+		}
+		filter.AfterSeq = seq
+	}
+
+	partyMu.RLock()
+	pr, err := requestParty(c)
+	if err == nil {
+		filter.PartyID = pr.ID
+		filter.CampaignID = campaignOf(pr).ID
+	}
+	partyMu.RUnlock()
+	if err != nil {
+		return apiError(c, http.StatusNotFound, err)
+	}
+
+	// Subscribe before catching up, so nothing committed in between is missed
+	live := subscribe(filter)
+	defer unsubscribe(live)
+	// The journal is read under stateMu, so no request is halfway through appending to it
+	var missed []Event
+	if catchUp {
+		stateMu.Lock()
+		missed, err = ReadJournal(filter)
+		stateMu.Unlock()
+		if err != nil {
+			return apiError(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	lastSeq := filter.AfterSeq
+	send := func(e Event) error {
+		if e.Seq <= lastSeq || e.Type == EventSnapshot {
+			return nil
+		}
+		e.Patch = nil
+		e.Snapshot = nil
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Type, data); err != nil {
+			return err
+		}
+		lastSeq = e.Seq
+		return nil
+	}
+	for _, e := range missed {
+		if err := send(e); err != nil {
+			return nil
+		}
+	}
+	w.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case e, ok := <-live:
+			if !ok {
+				return nil
+			}
+			if err := send(e); err != nil {
+				return nil
+			}
+			w.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			w.Flush()
+		}
+	}
+}
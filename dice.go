@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// DICE
+
+// rollDie rolls a single die with the given number of sides.
+// It is a variable so the roller can be swapped out for fixed results.
+var rollDie = func(sides int) int {
+	if sides < 1 {
+		return 0
+	}
+	return rand.IntN(sides) + 1
+}
+
+// RollDice rolls count dice of the given size and returns the total.
+func RollDice(count, sides int) int {
+	total := 0
+	for i := 0; i < count; i++ {
+		total += rollDie(sides)
+	}
+	return total
+}
+
+// DiceExpression is a parsed dice expression such as "2d4+1".
+type DiceExpression struct {
+	Count    int
+	Sides    int
+	Modifier int
+}
+
+// ParseDice parses expressions of the form "NdS", "NdS+M", "NdS-M", or a flat number.
+func ParseDice(expr string) (DiceExpression, error) {
+	s := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(expr)), " ", "")
+	if s == "" {
+		return DiceExpression{}, fmt.Errorf("empty dice expression")
+	}
+
+	var d DiceExpression
+	dicePart, modPart := s, ""
+	if i := strings.LastIndexAny(s, "+-"); i > 0 {
+		dicePart, modPart = s[:i], s[i:]
+	}
+	if modPart != "" {
+		m, err := strconv.Atoi(modPart)
+		if err != nil {
+			return DiceExpression{}, fmt.Errorf("invalid dice expression %q", expr)
+		}
+		d.Modifier = m
+	}
+
+	count, sides, hasDice := strings.Cut(dicePart, "d")
+	if !hasDice {
+		flat, err := strconv.Atoi(dicePart)
+		if err != nil {
+			return DiceExpression{}, fmt.Errorf("invalid dice expression %q", expr)
+		}
+		d.Modifier += flat
+		return d, nil
+	}
+	d.Count = 1
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return DiceExpression{}, fmt.Errorf("invalid dice expression %q", expr)
+		}
+		d.Count = n
+	}
+	n, err := strconv.Atoi(sides)
+	if err != nil || n < 1 {
+		return DiceExpression{}, fmt.Errorf("invalid dice expression %q", expr)
+	}
+	d.Sides = n
+	return d, nil
+}
+
+// Roll rolls the expression.
+func (d DiceExpression) Roll() int {
+	return RollDice(d.Count, d.Sides) + d.Modifier
+}
+
+// RollExpression parses and rolls a dice expression in one step.
+func RollExpression(expr string) (int, error) {
+	d, err := ParseDice(expr)
+	if err != nil {
+		return 0, err
+	}
+	return d.Roll(), nil
+}
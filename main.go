@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
 	"html/template"
 	"io"
 	"log"
-	"net/http"
+	"os"
 	"path/filepath"
 
 	"github.com/labstack/echo/v4"
@@ -24,9 +27,34 @@ func (t *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c
 var tmpl *template.Template
 
 func main() {
+	verify := flag.Bool("verify", false, "replay the event journal, check it matches the saved campaigns, and exit")
+	flag.Parse()
+	if *verify {
+		os.Exit(verifyJournal())
+	}
+
+	// Load the B/X spell list and monsters
+	if err := LoadSpellCatalog(); err != nil {
+		log.Fatalf("Failed to load spell catalog: %v", err)
+	}
+	if err := LoadMonsterCatalog(); err != nil {
+		log.Fatalf("Failed to load monster catalog: %v", err)
+	}
+	// The journal goes first, so the snapshots taken when the save is loaded continue its sequence
+	if err := LoadJournal(); err != nil {
+		log.Fatalf("Failed to load the event journal: %v", err)
+	}
+	// Pick up where the last session was saved, or start the default campaign
+	if _, err := os.Stat(SavePath); err == nil {
+		if err := LoadCampaigns(SavePath); err != nil {
+			log.Fatalf("Failed to load saved campaigns: %v", err)
+		}
+	}
+	InitCampaigns()
+
 	// Parse all templates in the templates folder
 	var err error
-	tmpl, err = template.ParseGlob(filepath.Join("templates", "*.html"))
+	tmpl, err = template.New("").Funcs(templateFuncs).ParseGlob(filepath.Join("templates", "*.html"))
 	if err != nil {
 		log.Fatalf("Failed to parse templates: %v", err)
 	}
@@ -40,7 +68,8 @@ func main() {
 	e.Static("/static", "static")
 
 	// Routes
-	e.GET("/", indexHandler)
+	registerPageRoutes(e)
+	registerAPIRoutes(e)
 
 	log.Println("Server running on http://localhost:8080")
 	if err := e.Start(":8080"); err != nil {
@@ -48,10 +77,37 @@ func main() {
 	}
 }
 
-// Handlers
-func indexHandler(c echo.Context) error {
-	data := map[string]any{
-		"Title": "Welcome to Dungeon Party",
+// verifyJournal is the -verify mode. It returns the exit status: 0 if every party replays to what
+// was saved, 1 if not.
+func verifyJournal() int {
+	if err := LoadSpellCatalog(); err != nil {
+		log.Fatalf("Failed to load spell catalog: %v", err)
+	}
+	data, err := os.ReadFile(SavePath)
+	if err != nil {
+		log.Fatalf("Failed to read saved campaigns: %v", err)
+	}
+	var save SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		log.Fatalf("Failed to read saved campaigns: %v", err)
+	}
+	reports, mismatched, err := VerifyJournal(save)
+	if err != nil {
+		log.Fatalf("Failed to replay the journal: %v", err)
+	}
+	for _, r := range reports {
+		fmt.Printf("campaign %d party %d: %d events, %d changes, %d snapshots, replayed to event %d\n",
+			r.CampaignID, r.PartyID, r.Events, r.Patches, r.Snapshots, r.UntilSeq)
+		if len(r.Drift) > 0 {
+			fmt.Printf("  snapshots that differ from the replay before them: %v\n", r.Drift)
+		}
+	}
+	for _, r := range mismatched {
+		fmt.Printf("campaign %d party %d: replay does not match the save\n", r.CampaignID, r.PartyID)
+	}
+	if len(mismatched) > 0 {
+		return 1
 	}
-	return c.Render(http.StatusOK, "index.html", data)
+	fmt.Printf("%d parties verified\n", len(reports))
+	return 0
 }
@@ -0,0 +1,57 @@
+package main
+
+// MONSTER CATALOG
+
+// bxMonsters is a selection of monsters from the B/X rules.
+// Attacks with a special effect and no damage of their own (paralysis, energy drain, rust) deal "0".
+var bxMonsters = []Monster{
+	{ID: 1, Name: "Bandit", ArmorClass: 6, HitDice: "1", Attacks: []MonsterAttack{{"weapon", "1d6"}}, Movement: "120' (40')", SavesAs: "T1", Morale: 8, TreasureType: "U (A)", Alignment: AlignmentNeutral, XPValue: 10},
+	{ID: 2, Name: "Berserker", ArmorClass: 7, HitDice: "1+1", Attacks: []MonsterAttack{{"weapon", "1d8"}}, Movement: "120' (40')", SavesAs: "F1", Morale: 12, TreasureType: "P (B)", Alignment: AlignmentNeutral, XPValue: 19},
+	{ID: 3, Name: "Bugbear", ArmorClass: 5, HitDice: "3+1", Attacks: []MonsterAttack{{"weapon", "2d4"}}, Movement: "90' (30')", SavesAs: "F3", Morale: 9, TreasureType: "B", Alignment: AlignmentChaotic, XPValue: 75},
+	{ID: 4, Name: "Carrion Crawler", ArmorClass: 7, HitDice: "3+1", Attacks: []MonsterAttack{{"tentacles", "0"}}, Movement: "120' (40')", SavesAs: "F2", Morale: 9, TreasureType: "B", Alignment: AlignmentNeutral, XPValue: 135},
+	{ID: 5, Name: "Doppelganger", ArmorClass: 5, HitDice: "4", Attacks: []MonsterAttack{{"bite", "1d12"}}, Movement: "90' (30')", SavesAs: "F10", Morale: 10, TreasureType: "E", Alignment: AlignmentChaotic, XPValue: 125},
+	{ID: 6, Name: "Dwarf", ArmorClass: 4, HitDice: "1", Attacks: []MonsterAttack{{"weapon", "1d8"}}, Movement: "60' (20')", SavesAs: "D1", Morale: 8, TreasureType: "G", Alignment: AlignmentLawful, XPValue: 10},
+	{ID: 7, Name: "Elf", ArmorClass: 5, HitDice: "1+1", Attacks: []MonsterAttack{{"weapon", "1d8"}}, Movement: "120' (40')", SavesAs: "E1", Morale: 8, TreasureType: "E", Alignment: AlignmentNeutral, XPValue: 19},
+	{ID: 8, Name: "Gargoyle", ArmorClass: 5, HitDice: "4", Attacks: []MonsterAttack{{"claw", "1d3"}, {"claw", "1d3"}, {"bite", "1d6"}, {"horn", "1d4"}}, Movement: "90' (30') / 150' (50') flying", SavesAs: "F8", Morale: 11, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 175},
+	{ID: 9, Name: "Gelatinous Cube", ArmorClass: 8, HitDice: "4", Attacks: []MonsterAttack{{"touch", "2d4"}}, Movement: "60' (20')", SavesAs: "F2", Morale: 12, TreasureType: "V", Alignment: AlignmentNeutral, XPValue: 125},
+	{ID: 10, Name: "Ghoul", ArmorClass: 6, HitDice: "2", Attacks: []MonsterAttack{{"claw", "1d3"}, {"claw", "1d3"}, {"bite", "1d3"}}, Movement: "90' (30')", SavesAs: "F2", Morale: 9, TreasureType: "B", Alignment: AlignmentChaotic, XPValue: 25},
+	{ID: 11, Name: "Giant Ant", ArmorClass: 3, HitDice: "4", Attacks: []MonsterAttack{{"bite", "2d6"}}, Movement: "180' (60')", SavesAs: "F2", Morale: 7, TreasureType: "U", Alignment: AlignmentNeutral, XPValue: 75},
+	{ID: 12, Name: "Giant Bat", ArmorClass: 6, HitDice: "2", Attacks: []MonsterAttack{{"bite", "1d4"}}, Movement: "180' (60') flying", SavesAs: "F1", Morale: 8, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 20},
+	{ID: 13, Name: "Giant Centipede", ArmorClass: 9, HitDice: "1/2", Attacks: []MonsterAttack{{"bite", "0"}}, Movement: "60' (20')", SavesAs: "NH", Morale: 7, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 6},
+	{ID: 14, Name: "Giant Fire Beetle", ArmorClass: 4, HitDice: "1+2", Attacks: []MonsterAttack{{"bite", "2d4"}}, Movement: "120' (40')", SavesAs: "F1", Morale: 7, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 15},
+	{ID: 15, Name: "Giant Rat", ArmorClass: 7, HitDice: "1/2", Attacks: []MonsterAttack{{"bite", "1d3"}}, Movement: "120' (40') / 60' (20') swimming", SavesAs: "F1", Morale: 8, TreasureType: "C", Alignment: AlignmentNeutral, XPValue: 5},
+	{ID: 16, Name: "Gnoll", ArmorClass: 5, HitDice: "2", Attacks: []MonsterAttack{{"weapon", "2d4"}}, Movement: "90' (30')", SavesAs: "F2", Morale: 8, TreasureType: "D", Alignment: AlignmentChaotic, XPValue: 20},
+	{ID: 17, Name: "Gnome", ArmorClass: 5, HitDice: "1", Attacks: []MonsterAttack{{"weapon", "1d6"}}, Movement: "60' (20')", SavesAs: "D1", Morale: 8, TreasureType: "C", Alignment: AlignmentNeutral, XPValue: 10},
+	{ID: 18, Name: "Goblin", ArmorClass: 6, HitDice: "1-1", Attacks: []MonsterAttack{{"weapon", "1d6"}}, Movement: "60' (20')", SavesAs: "NH", Morale: 7, TreasureType: "R (C)", Alignment: AlignmentChaotic, XPValue: 5},
+	{ID: 19, Name: "Harpy", ArmorClass: 7, HitDice: "3", Attacks: []MonsterAttack{{"claw", "1d4"}, {"claw", "1d4"}, {"weapon", "1d6"}}, Movement: "60' (20') / 150' (50') flying", SavesAs: "F6", Morale: 7, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 50},
+	{ID: 20, Name: "Hobgoblin", ArmorClass: 6, HitDice: "1+1", Attacks: []MonsterAttack{{"weapon", "1d8"}}, Movement: "90' (30')", SavesAs: "F1", Morale: 8, TreasureType: "D", Alignment: AlignmentChaotic, XPValue: 15},
+	{ID: 21, Name: "Killer Bee", ArmorClass: 7, HitDice: "1/2", Attacks: []MonsterAttack{{"sting", "1d3"}}, Movement: "150' (50') flying", SavesAs: "F1", Morale: 9, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 6},
+	{ID: 22, Name: "Kobold", ArmorClass: 7, HitDice: "1/2", Attacks: []MonsterAttack{{"weapon", "1d4"}}, Movement: "60' (20')", SavesAs: "NH", Morale: 6, TreasureType: "P (J)", Alignment: AlignmentChaotic, XPValue: 5},
+	{ID: 23, Name: "Lizard Man", ArmorClass: 5, HitDice: "2+1", Attacks: []MonsterAttack{{"weapon", "1d6+1"}}, Movement: "60' (20') / 120' (40') swimming", SavesAs: "F2", Morale: 12, TreasureType: "D", Alignment: AlignmentNeutral, XPValue: 25},
+	{ID: 24, Name: "Medusa", ArmorClass: 8, HitDice: "4", Attacks: []MonsterAttack{{"snakebite", "1d6"}}, Movement: "90' (30')", SavesAs: "F4", Morale: 8, TreasureType: "F", Alignment: AlignmentChaotic, XPValue: 175},
+	{ID: 25, Name: "Minotaur", ArmorClass: 6, HitDice: "6", Attacks: []MonsterAttack{{"gore", "1d6"}, {"bite", "1d6"}}, Movement: "120' (40')", SavesAs: "F6", Morale: 12, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 275},
+	{ID: 26, Name: "Neanderthal", ArmorClass: 8, HitDice: "2", Attacks: []MonsterAttack{{"weapon", "2d4"}}, Movement: "120' (40')", SavesAs: "F2", Morale: 7, TreasureType: "C", Alignment: AlignmentLawful, XPValue: 20},
+	{ID: 27, Name: "Ogre", ArmorClass: 5, HitDice: "4+1", Attacks: []MonsterAttack{{"club", "1d10"}}, Movement: "90' (30')", SavesAs: "F4", Morale: 10, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 125},
+	{ID: 28, Name: "Orc", ArmorClass: 6, HitDice: "1", Attacks: []MonsterAttack{{"weapon", "1d6"}}, Movement: "120' (40')", SavesAs: "F1", Morale: 8, TreasureType: "D", Alignment: AlignmentChaotic, XPValue: 10},
+	{ID: 29, Name: "Owl Bear", ArmorClass: 5, HitDice: "5", Attacks: []MonsterAttack{{"claw", "1d8"}, {"claw", "1d8"}, {"bite", "1d8"}}, Movement: "120' (40')", SavesAs: "F3", Morale: 9, TreasureType: "C", Alignment: AlignmentNeutral, XPValue: 175},
+	{ID: 30, Name: "Rust Monster", ArmorClass: 2, HitDice: "5", Attacks: []MonsterAttack{{"touch", "0"}}, Movement: "120' (40')", SavesAs: "F3", Morale: 7, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 300},
+	{ID: 31, Name: "Skeleton", ArmorClass: 7, HitDice: "1", Attacks: []MonsterAttack{{"weapon", "1d6"}}, Movement: "60' (20')", SavesAs: "F1", Morale: 12, TreasureType: "None", Alignment: AlignmentChaotic, XPValue: 10},
+	{ID: 32, Name: "Black Widow Spider", ArmorClass: 6, HitDice: "3", Attacks: []MonsterAttack{{"bite", "2d6"}}, Movement: "60' (20') / 120' (40') in web", SavesAs: "F2", Morale: 8, TreasureType: "U", Alignment: AlignmentNeutral, XPValue: 50},
+	{ID: 33, Name: "Stirge", ArmorClass: 7, HitDice: "1", Attacks: []MonsterAttack{{"beak", "1d3"}}, Movement: "10' (3') / 180' (60') flying", SavesAs: "F2", Morale: 9, TreasureType: "L", Alignment: AlignmentNeutral, XPValue: 13},
+	{ID: 34, Name: "Thoul", ArmorClass: 6, HitDice: "3", Attacks: []MonsterAttack{{"claw", "1d3"}, {"claw", "1d3"}}, Movement: "120' (40')", SavesAs: "F3", Morale: 10, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 65},
+	{ID: 35, Name: "Troglodyte", ArmorClass: 5, HitDice: "2", Attacks: []MonsterAttack{{"claw", "1d4"}, {"claw", "1d4"}, {"bite", "1d4"}}, Movement: "120' (40')", SavesAs: "F2", Morale: 9, TreasureType: "A", Alignment: AlignmentChaotic, XPValue: 25},
+	{ID: 36, Name: "Wererat", ArmorClass: 7, HitDice: "3", Attacks: []MonsterAttack{{"bite", "1d4"}}, Movement: "120' (40')", SavesAs: "F3", Morale: 8, TreasureType: "C", Alignment: AlignmentChaotic, XPValue: 50},
+	{ID: 37, Name: "Wight", ArmorClass: 5, HitDice: "3", Attacks: []MonsterAttack{{"touch", "0"}}, Movement: "90' (30')", SavesAs: "F3", Morale: 12, TreasureType: "B", Alignment: AlignmentChaotic, XPValue: 50},
+	{ID: 38, Name: "Wolf", ArmorClass: 7, HitDice: "2+2", Attacks: []MonsterAttack{{"bite", "1d6"}}, Movement: "180' (60')", SavesAs: "F1", Morale: 8, TreasureType: "None", Alignment: AlignmentNeutral, XPValue: 25},
+	{ID: 39, Name: "Zombie", ArmorClass: 8, HitDice: "2", Attacks: []MonsterAttack{{"claw", "1d8"}}, Movement: "90' (30')", SavesAs: "F1", Morale: 12, TreasureType: "None", Alignment: AlignmentChaotic, XPValue: 20},
+}
+
+// LoadMonsterCatalog registers every monster in the B/X catalog.
+func LoadMonsterCatalog() error {
+	for _, m := range bxMonsters {
+		if err := RegisterMonster(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
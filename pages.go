@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PAGES
+
+// The pages render the default party server-side, so they work without JavaScript. main.js
+// enhances them when it is available.
+func registerPageRoutes(e *echo.Echo) {
+	pages := e.Group("", scopeParty)
+	pages.GET("/", indexHandler)
+	pages.GET("/characters/:id", characterPageHandler)
+	pages.GET("/inventory", inventoryPageHandler)
+	pages.GET("/spells", spellsPageHandler)
+	registerSheetRoutes(pages)
+}
+
+// templateFuncs are available to every template.
+var templateFuncs = template.FuncMap{
+	"signed":    func(n int) string { return fmt.Sprintf("%+d", n) },
+	"join":      strings.Join,
+	"className": className,
+}
+
+// characterRow is a character's line in the party overview.
+type characterRow struct {
+	ID           int
+	Name         string
+	Class        string
+	Level        int
+	HitPoints    int
+	MaxHitPoints int
+	ArmorClass   int
+	THAC0        int
+	Status       CharacterStatus
+	Employer     string // for retainers
+}
+
+// itemRow is an item as listed on a page.
+type itemRow struct {
+	ID       int
+	Name     string
+	Type     ItemType
+	Equipped string // "armor" or "shield" when worn
+}
+
+// inventoryGroup is one location's items: a character's pack, the party's, storage, or limbo.
+type inventoryGroup struct {
+	Title       string
+	Location    ItemLocation
+	CharacterID int
+	Items       []itemRow
+}
+
+// spellRow is a spell as listed on a page.
+type spellRow struct {
+	Name  string
+	Level int
+	Cast  bool
+}
+
+// spellLevelRow is one level of a caster's spells: how many they can memorize and what they have.
+type spellLevelRow struct {
+	Level     int
+	Slots     int
+	Memorized []spellRow
+}
+
+// casterSpells is everything on the spell page for one caster.
+type casterSpells struct {
+	ID         int
+	Name       string
+	Class      string
+	Level      int
+	Preparing  bool
+	Levels     []spellLevelRow
+	Known      []spellRow
+	Spellbooks []string
+}
+
+// pageData starts the data for a page with the title and what every page header shows.
+func pageData(title string) map[string]any {
+	data := map[string]any{
+		"Title": title,
+		"Day":   State.Day,
+		"Hour":  State.Turn / TurnsPerHour,
+	}
+	if campaign := campaignOf(activeParty); campaign != nil {
+		data["Campaign"] = campaign.Name
+		data["Session"] = campaign.Session
+	}
+	if activeParty != nil {
+		data["Party"] = activeParty.Name
+	}
+	return data
+}
+
+// Handlers
+func indexHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	data := pageData("Dungeon Party")
+	rows := make([]characterRow, 0, len(State.Characters))
+	for i := range State.Characters {
+		ch := &State.Characters[i]
+		row := characterRow{
+			ID:           ch.ID,
+			Name:         ch.Name,
+			Class:        className(ch.Class),
+			Level:        ch.Level,
+			HitPoints:    ch.CurrentHitPoints,
+			MaxHitPoints: ch.MaximumHitPoints,
+			ArmorClass:   CharacterArmorClass(ch),
+			THAC0:        characterTHAC0(ch.Class, ch.Level),
+			Status:       ch.Status,
+		}
+		if ch.Retainer != nil {
+			if employer, err := FindChar(&State, ch.Retainer.EmployerID); err == nil {
+				row.Employer = employer.Name
+			}
+		}
+		rows = append(rows, row)
+	}
+	data["Characters"] = rows
+	return c.Render(http.StatusOK, "index.html", data)
+}
+
+func characterPageHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "invalid character id")
+	}
+	stateMu.Lock()
+	defer stateMu.Unlock()
+	ch, err := FindChar(&State, id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	// The page shows the character's sheet, so it can't disagree with the printed one
+	sheet, err := BuildCharacterSheet(id, &State)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	data := pageData(ch.Name)
+	data["Character"] = ch
+	data["Sheet"] = sheet
+	return c.Render(http.StatusOK, "character.html", data)
+}
+
+func inventoryPageHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	data := pageData("Inventory")
+	data["Groups"] = inventoryGroups(&State)
+	return c.Render(http.StatusOK, "inventory.html", data)
+}
+
+func spellsPageHandler(c echo.Context) error {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	data := pageData("Spells")
+	var casters []casterSpells
+	for i := range State.Characters {
+		ch := &State.Characters[i]
+		if len(ch.Spellcasting) == 0 {
+			continue
+		}
+		casters = append(casters, spellsFor(ch))
+	}
+	data["Casters"] = casters
+	return c.Render(http.StatusOK, "spells.html", data)
+}
+
+// Page helpers
+
+func className(class CharacterClass) string {
+	switch class {
+	case ClassNone:
+		return "No class"
+	case ClassMagicUser:
+		return "Magic-User"
+	}
+	return strings.ToUpper(string(class[:1])) + string(class[1:])
+}
+
+func itemRowFor(it *Item, ch *Character) itemRow {
+	row := itemRow{ID: it.ID, Name: it.Name, Type: it.Type}
+	if ch != nil {
+		switch it.ID {
+		case ch.ArmorID:
+			row.Equipped = "armor"
+		case ch.ShieldID:
+			row.Equipped = "shield"
+		}
+	}
+	return row
+}
+
+// carriedItemRows lists what a character carries, in the order they picked it up.
+func carriedItemRows(ch *Character) []itemRow {
+	rows := []itemRow{}
+	for _, id := range ch.Items {
+		if it, err := FindItemByID(id); err == nil {
+			rows = append(rows, itemRowFor(it, ch))
+		}
+	}
+	return rows
+}
+
+// inventoryGroups sorts every item into its location: one group per character, then the party's
+// shared items, storage, and limbo.
+func inventoryGroups(p *Party) []inventoryGroup {
+	groups := []inventoryGroup{}
+	for i := range p.Characters {
+		ch := &p.Characters[i]
+		groups = append(groups, inventoryGroup{
+			Title:       ch.Name,
+			Location:    LocationCharacter,
+			CharacterID: ch.ID,
+			Items:       carriedItemRows(ch),
+		})
+	}
+	buckets := []inventoryGroup{
+		{Title: "Party", Location: LocationParty, Items: []itemRow{}},
+		{Title: "Storage", Location: LocationStorage, Items: []itemRow{}},
+		{Title: "Limbo", Location: LocationLimbo, Items: []itemRow{}},
+	}
+	for _, it := range AllItems() {
+		for i := range buckets {
+			if it.Location == buckets[i].Location {
+				buckets[i].Items = append(buckets[i].Items, itemRowFor(it, nil))
+			}
+		}
+	}
+	return append(groups, buckets...)
+}
+
+// spellsFor lists a caster's spell slots and memorized spells by level, the spells they know, and
+// their spellbooks.
+func spellsFor(ch *Character) casterSpells {
+	cs := casterSpells{
+		ID:        ch.ID,
+		Name:      ch.Name,
+		Class:     className(ch.Class),
+		Level:     ch.Level,
+		Preparing: ch.PreparingSpells,
+		Known:     []spellRow{},
+	}
+	for level := 1; level <= MaxSpellLevelAvailable(ch.Class, ch.Level); level++ {
+		row := spellLevelRow{Level: level, Slots: GetSpellSlots(ch.Class, ch.Level, level), Memorized: []spellRow{}}
+		for _, ms := range ch.MemorizedSpells {
+			if spell, ok := SpellsByID[ms.SpellID]; ok && spell.Level == level {
+				row.Memorized = append(row.Memorized, spellRow{SpellFormName(spell, ms.Reversed), level, ms.Cast})
+			}
+		}
+		cs.Levels = append(cs.Levels, row)
+	}
+	for _, id := range KnownSpellIDs(ch) {
+		if spell, ok := SpellsByID[id]; ok {
+			cs.Known = append(cs.Known, spellRow{Name: spell.Name, Level: spell.Level})
+		}
+	}
+	for _, sb := range ownedSpellbooks(ch) {
+		cs.Spellbooks = append(cs.Spellbooks, sb.Name)
+	}
+	return cs
+}
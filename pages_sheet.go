@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CHARACTER SHEET PAGES
+
+// The printable character sheet, as a page made for printing and as a PDF.
+func registerSheetRoutes(pages *echo.Group) {
+	pages.GET("/characters/:id/sheet", sheetPageHandler)
+	pages.GET("/characters/:id/sheet.pdf", sheetPDFHandler)
+}
+
+func sheetPageHandler(c echo.Context) error {
+	return withSheet(c, func(s CharacterSheet) error {
+		return c.Render(http.StatusOK, "sheet.html", map[string]any{"Title": s.Name, "Sheet": s, "ID": c.Param("id")})
+	})
+}
+
+func sheetPDFHandler(c echo.Context) error {
+	return withSheet(c, func(s CharacterSheet) error {
+		filename := strings.Map(func(r rune) rune {
+			if r == '"' || r == '\\' || r < 32 || r > 126 {
+				return '_'
+			}
+			return r
+		}, s.Name)
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename+".pdf"))
+		return c.Blob(http.StatusOK, "application/pdf", characterSheetPDF(s))
+	})
+}
+
+// withSheet builds the sheet for the :id character and runs fn with it.
+func withSheet(c echo.Context, fn func(s CharacterSheet) error) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "invalid character id")
+	}
+	stateMu.Lock()
+	s, err := BuildCharacterSheet(id, &State)
+	stateMu.Unlock()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return fn(s)
+}
+
+// PDF layout
+
+// sheetLayout draws a character sheet down the page, starting a new page when it runs out of room.
+type sheetLayout struct {
+	doc *pdfDocument
+	y   float64
+}
+
+const (
+	sheetMargin     = 48.0
+	sheetLineHeight = 14.0
+)
+
+// room starts a new page unless there is room for lines more rows on this one.
+func (l *sheetLayout) room(lines int) {
+	if l.y+float64(lines)*sheetLineHeight > pdfPageHeight-sheetMargin {
+		l.doc.AddPage()
+		l.y = sheetMargin
+	}
+}
+
+// heading starts a section with its title underlined.
+func (l *sheetLayout) heading(title string) {
+	l.room(3)
+	l.y += sheetLineHeight
+	l.doc.Text(sheetMargin, l.y, 12, true, title)
+	l.doc.Line(sheetMargin, l.y+4, pdfPageWidth-sheetMargin, l.y+4)
+	l.y += sheetLineHeight + 4
+}
+
+// row prints cells at the given x offsets from the margin.
+func (l *sheetLayout) row(offsets []float64, cells ...string) {
+	l.room(1)
+	for i, cell := range cells {
+		l.doc.Text(sheetMargin+offsets[i], l.y, 10, false, cell)
+	}
+	l.y += sheetLineHeight
+}
+
+// paragraph prints text wrapped to the width of the page.
+func (l *sheetLayout) paragraph(text string) {
+	const maxChars = 100 // Helvetica at 10pt fits about this many across the page
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > maxChars {
+			l.row([]float64{0}, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		l.row([]float64{0}, line)
+	}
+}
+
+// characterSheetPDF lays a character sheet out as a PDF.
+func characterSheetPDF(s CharacterSheet) []byte {
+	doc := newPDFDocument()
+	l := &sheetLayout{doc: doc, y: sheetMargin}
+
+	doc.Text(sheetMargin, l.y+14, 20, true, s.Name)
+	l.y += 30
+	subtitle := fmt.Sprintf("%s %d", className(s.Class), s.Level)
+	if s.Alignment != AlignmentNone {
+		subtitle += ", " + string(s.Alignment)
+	}
+	subtitle += " - " + string(s.Status)
+	if s.Employer != "" {
+		subtitle += " - retainer of " + s.Employer
+	}
+	l.row([]float64{0}, subtitle)
+	l.row([]float64{0}, fmt.Sprintf("Printed on day %d", s.Day))
+
+	l.heading("Abilities")
+	cols := []float64{0, 90, 130, 170}
+	for _, a := range s.Abilities {
+		l.row(cols, a.Name, fmt.Sprint(a.Score), fmt.Sprintf("%+d", a.Modifier), a.Note)
+	}
+
+	l.heading("Combat")
+	cols = []float64{0, 90, 260, 350}
+	l.row(cols, "Hit points", fmt.Sprintf("%d / %d", s.HitPoints, s.MaxHitPoints), "Experience", fmt.Sprint(s.Experience))
+	l.row(cols, "Armor class", fmt.Sprint(s.ArmorClass), "Gold", fmt.Sprint(s.Gold))
+	l.row(cols, "THAC0", fmt.Sprint(s.THAC0))
+
+	l.heading("Saving Throws")
+	cols = []float64{0, 170}
+	l.row(cols, "Death ray or poison", fmt.Sprint(s.SavingThrows.DeathPoison))
+	l.row(cols, "Magic wands", fmt.Sprint(s.SavingThrows.Wands))
+	l.row(cols, "Paralysis or turn to stone", fmt.Sprint(s.SavingThrows.ParalysisStone))
+	l.row(cols, "Dragon breath", fmt.Sprint(s.SavingThrows.BreathAttacks))
+	l.row(cols, "Rods, staves or spells", fmt.Sprint(s.SavingThrows.SpellsRodsStaff))
+
+	l.heading("Attack Matrix")
+	l.room(2)
+	top := l.y - 10
+	cols = []float64{0}
+	acs := []string{"Target AC"}
+	rolls := []string{"Roll needed"}
+	for i, e := range s.AttackMatrix {
+		cols = append(cols, 80+float64(i)*32)
+		acs = append(acs, fmt.Sprint(e.ArmorClass))
+		rolls = append(rolls, fmt.Sprint(e.Roll))
+	}
+	l.row(cols, acs...)
+	l.row(cols, rolls...)
+	doc.Rect(sheetMargin-4, top, pdfPageWidth-2*sheetMargin+8, l.y-top-8)
+
+	l.heading("Equipment")
+	if len(s.Equipment) == 0 {
+		l.row([]float64{0}, "Carrying nothing.")
+	}
+	cols = []float64{0, 240, 330}
+	for _, it := range s.Equipment {
+		equipped := ""
+		if it.Equipped != "" {
+			equipped = "equipped " + it.Equipped
+		}
+		l.row(cols, it.Name, string(it.Type), equipped)
+	}
+
+	if len(s.SpellLevels) > 0 {
+		l.heading("Spells")
+		cols = []float64{0, 60, 120}
+		for _, sl := range s.SpellLevels {
+			memorized := "none"
+			if len(sl.Memorized) > 0 {
+				memorized = strings.Join(sl.Memorized, ", ")
+			}
+			l.row(cols, fmt.Sprintf("Level %d", sl.Level), fmt.Sprintf("%d / %d", len(sl.Memorized), sl.Slots), memorized)
+		}
+		if len(s.KnownSpells) > 0 {
+			l.paragraph("Known: " + strings.Join(s.KnownSpells, ", "))
+		}
+	}
+
+	if len(s.Effects) > 0 {
+		l.heading("Effects")
+		for _, e := range s.Effects {
+			l.row([]float64{0}, e)
+		}
+	}
+	if len(s.Retainers) > 0 {
+		l.heading("Retainers")
+		l.paragraph(strings.Join(s.Retainers, ", "))
+	}
+	return doc.Bytes()
+}
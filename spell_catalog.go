@@ -0,0 +1,127 @@
+package main
+
+// SPELL CATALOG
+
+// bxSpells is the B/X spell list. Cleric spells are numbered from 101, Magic User spells from 201.
+// Reversible spells carry the name of their reversed form.
+var bxSpells = []Spell{
+	// Cleric
+	{ID: 101, Name: "Cure Light Wounds", Level: 1, Type: SpellDivine, Reversible: true, ReversedName: "Cause Light Wounds"},
+	{ID: 102, Name: "Detect Evil", Level: 1, Type: SpellDivine},
+	{ID: 103, Name: "Detect Magic", Level: 1, Type: SpellDivine},
+	{ID: 104, Name: "Light", Level: 1, Type: SpellDivine, Reversible: true, ReversedName: "Darkness"},
+	{ID: 105, Name: "Protection from Evil", Level: 1, Type: SpellDivine},
+	{ID: 106, Name: "Purify Food and Water", Level: 1, Type: SpellDivine},
+	{ID: 107, Name: "Remove Fear", Level: 1, Type: SpellDivine, Reversible: true, ReversedName: "Cause Fear"},
+	{ID: 108, Name: "Resist Cold", Level: 1, Type: SpellDivine},
+	{ID: 109, Name: "Bless", Level: 2, Type: SpellDivine, Reversible: true, ReversedName: "Blight"},
+	{ID: 110, Name: "Find Traps", Level: 2, Type: SpellDivine},
+	{ID: 111, Name: "Hold Person", Level: 2, Type: SpellDivine},
+	{ID: 112, Name: "Know Alignment", Level: 2, Type: SpellDivine},
+	{ID: 113, Name: "Resist Fire", Level: 2, Type: SpellDivine},
+	{ID: 114, Name: "Silence 15' Radius", Level: 2, Type: SpellDivine},
+	{ID: 115, Name: "Snake Charm", Level: 2, Type: SpellDivine},
+	{ID: 116, Name: "Speak with Animals", Level: 2, Type: SpellDivine},
+	{ID: 117, Name: "Continual Light", Level: 3, Type: SpellDivine, Reversible: true, ReversedName: "Continual Darkness"},
+	{ID: 118, Name: "Cure Disease", Level: 3, Type: SpellDivine, Reversible: true, ReversedName: "Cause Disease"},
+	{ID: 119, Name: "Growth of Animal", Level: 3, Type: SpellDivine},
+	{ID: 120, Name: "Locate Object", Level: 3, Type: SpellDivine},
+	{ID: 121, Name: "Remove Curse", Level: 3, Type: SpellDivine, Reversible: true, ReversedName: "Curse"},
+	{ID: 122, Name: "Striking", Level: 3, Type: SpellDivine},
+	{ID: 123, Name: "Create Water", Level: 4, Type: SpellDivine},
+	{ID: 124, Name: "Cure Serious Wounds", Level: 4, Type: SpellDivine, Reversible: true, ReversedName: "Cause Serious Wounds"},
+	{ID: 125, Name: "Neutralize Poison", Level: 4, Type: SpellDivine},
+	{ID: 126, Name: "Protection from Evil 10' Radius", Level: 4, Type: SpellDivine},
+	{ID: 127, Name: "Speak with Plants", Level: 4, Type: SpellDivine},
+	{ID: 128, Name: "Sticks to Snakes", Level: 4, Type: SpellDivine},
+	{ID: 129, Name: "Commune", Level: 5, Type: SpellDivine},
+	{ID: 130, Name: "Create Food", Level: 5, Type: SpellDivine},
+	{ID: 131, Name: "Dispel Evil", Level: 5, Type: SpellDivine},
+	{ID: 132, Name: "Insect Plague", Level: 5, Type: SpellDivine},
+	{ID: 133, Name: "Quest", Level: 5, Type: SpellDivine, Reversible: true, ReversedName: "Remove Quest"},
+	{ID: 134, Name: "Raise Dead", Level: 5, Type: SpellDivine, Reversible: true, ReversedName: "Finger of Death"},
+
+	// Magic User
+	{ID: 201, Name: "Charm Person", Level: 1, Type: SpellArcane},
+	{ID: 202, Name: "Detect Magic", Level: 1, Type: SpellArcane},
+	{ID: 203, Name: "Floating Disc", Level: 1, Type: SpellArcane},
+	{ID: 204, Name: "Hold Portal", Level: 1, Type: SpellArcane},
+	{ID: 205, Name: "Light", Level: 1, Type: SpellArcane, Reversible: true, ReversedName: "Darkness"},
+	{ID: 206, Name: "Magic Missile", Level: 1, Type: SpellArcane},
+	{ID: 207, Name: "Protection from Evil", Level: 1, Type: SpellArcane},
+	{ID: 208, Name: "Read Languages", Level: 1, Type: SpellArcane},
+	{ID: 209, Name: "Read Magic", Level: 1, Type: SpellArcane},
+	{ID: 210, Name: "Shield", Level: 1, Type: SpellArcane},
+	{ID: 211, Name: "Sleep", Level: 1, Type: SpellArcane},
+	{ID: 212, Name: "Ventriloquism", Level: 1, Type: SpellArcane},
+	{ID: 213, Name: "Continual Light", Level: 2, Type: SpellArcane, Reversible: true, ReversedName: "Continual Darkness"},
+	{ID: 214, Name: "Detect Evil", Level: 2, Type: SpellArcane},
+	{ID: 215, Name: "Detect Invisible", Level: 2, Type: SpellArcane},
+	{ID: 216, Name: "ESP", Level: 2, Type: SpellArcane},
+	{ID: 217, Name: "Invisibility", Level: 2, Type: SpellArcane},
+	{ID: 218, Name: "Knock", Level: 2, Type: SpellArcane},
+	{ID: 219, Name: "Levitate", Level: 2, Type: SpellArcane},
+	{ID: 220, Name: "Locate Object", Level: 2, Type: SpellArcane},
+	{ID: 221, Name: "Mirror Image", Level: 2, Type: SpellArcane},
+	{ID: 222, Name: "Phantasmal Force", Level: 2, Type: SpellArcane},
+	{ID: 223, Name: "Web", Level: 2, Type: SpellArcane},
+	{ID: 224, Name: "Wizard Lock", Level: 2, Type: SpellArcane},
+	{ID: 225, Name: "Clairvoyance", Level: 3, Type: SpellArcane},
+	{ID: 226, Name: "Dispel Magic", Level: 3, Type: SpellArcane},
+	{ID: 227, Name: "Fire Ball", Level: 3, Type: SpellArcane},
+	{ID: 228, Name: "Fly", Level: 3, Type: SpellArcane},
+	{ID: 229, Name: "Haste", Level: 3, Type: SpellArcane},
+	{ID: 230, Name: "Hold Person", Level: 3, Type: SpellArcane},
+	{ID: 231, Name: "Infravision", Level: 3, Type: SpellArcane},
+	{ID: 232, Name: "Invisibility 10' Radius", Level: 3, Type: SpellArcane},
+	{ID: 233, Name: "Lightning Bolt", Level: 3, Type: SpellArcane},
+	{ID: 234, Name: "Protection from Evil 10' Radius", Level: 3, Type: SpellArcane},
+	{ID: 235, Name: "Protection from Normal Missiles", Level: 3, Type: SpellArcane},
+	{ID: 236, Name: "Water Breathing", Level: 3, Type: SpellArcane},
+	{ID: 237, Name: "Charm Monster", Level: 4, Type: SpellArcane},
+	{ID: 238, Name: "Confusion", Level: 4, Type: SpellArcane},
+	{ID: 239, Name: "Dimension Door", Level: 4, Type: SpellArcane},
+	{ID: 240, Name: "Growth of Plants", Level: 4, Type: SpellArcane},
+	{ID: 241, Name: "Hallucinatory Terrain", Level: 4, Type: SpellArcane},
+	{ID: 242, Name: "Massmorph", Level: 4, Type: SpellArcane},
+	{ID: 243, Name: "Polymorph Others", Level: 4, Type: SpellArcane},
+	{ID: 244, Name: "Polymorph Self", Level: 4, Type: SpellArcane},
+	{ID: 245, Name: "Remove Curse", Level: 4, Type: SpellArcane, Reversible: true, ReversedName: "Curse"},
+	{ID: 246, Name: "Wall of Fire", Level: 4, Type: SpellArcane},
+	{ID: 247, Name: "Wall of Ice", Level: 4, Type: SpellArcane},
+	{ID: 248, Name: "Wizard Eye", Level: 4, Type: SpellArcane},
+	{ID: 249, Name: "Animate Dead", Level: 5, Type: SpellArcane},
+	{ID: 250, Name: "Cloudkill", Level: 5, Type: SpellArcane},
+	{ID: 251, Name: "Conjure Elemental", Level: 5, Type: SpellArcane},
+	{ID: 252, Name: "Contact Higher Plane", Level: 5, Type: SpellArcane},
+	{ID: 253, Name: "Feeblemind", Level: 5, Type: SpellArcane},
+	{ID: 254, Name: "Hold Monster", Level: 5, Type: SpellArcane},
+	{ID: 255, Name: "Magic Jar", Level: 5, Type: SpellArcane},
+	{ID: 256, Name: "Pass-Wall", Level: 5, Type: SpellArcane},
+	{ID: 257, Name: "Telekinesis", Level: 5, Type: SpellArcane},
+	{ID: 258, Name: "Teleport", Level: 5, Type: SpellArcane},
+	{ID: 259, Name: "Transmute Rock to Mud", Level: 5, Type: SpellArcane, Reversible: true, ReversedName: "Transmute Mud to Rock"},
+	{ID: 260, Name: "Wall of Stone", Level: 5, Type: SpellArcane},
+	{ID: 261, Name: "Anti-Magic Shell", Level: 6, Type: SpellArcane},
+	{ID: 262, Name: "Control Weather", Level: 6, Type: SpellArcane},
+	{ID: 263, Name: "Death Spell", Level: 6, Type: SpellArcane},
+	{ID: 264, Name: "Disintegrate", Level: 6, Type: SpellArcane},
+	{ID: 265, Name: "Geas", Level: 6, Type: SpellArcane, Reversible: true, ReversedName: "Remove Geas"},
+	{ID: 266, Name: "Invisible Stalker", Level: 6, Type: SpellArcane},
+	{ID: 267, Name: "Lower Water", Level: 6, Type: SpellArcane},
+	{ID: 268, Name: "Move Earth", Level: 6, Type: SpellArcane},
+	{ID: 269, Name: "Part Water", Level: 6, Type: SpellArcane},
+	{ID: 270, Name: "Projected Image", Level: 6, Type: SpellArcane},
+	{ID: 271, Name: "Reincarnation", Level: 6, Type: SpellArcane},
+	{ID: 272, Name: "Stone to Flesh", Level: 6, Type: SpellArcane, Reversible: true, ReversedName: "Flesh to Stone"},
+}
+
+// LoadSpellCatalog registers every spell in the B/X catalog.
+func LoadSpellCatalog() error {
+	for _, s := range bxSpells {
+		if err := RegisterSpell(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // PARTY
 
@@ -9,9 +13,111 @@ var State = Party{
 }
 
 type Party struct {
-	Characters []Character
+	Characters     []Character
+	Day            int // Days elapsed in the campaign. Advanced by resting or by the clock
+	Turn           int // 10 minute turns elapsed in the current day
+	TurnsSinceRest int // Turns of exploration since the party last rested for a turn
+}
+
+// CAMPAIGNS
+
+type Campaign struct {
+	ID      int
+	Name    string
+	Session int // Current play session, counted from 1
+	Parties []*PartyRecord
+}
+
+// PartyRecord is one named party in a campaign and everything it owns. While the party is active
+// its contents live in State and the registries; the rest of the time they are kept here.
+type PartyRecord struct {
+	ID       int
+	Name     string
+	Party    Party
+	Registry PartyRegistry
+	History  History
+}
+
+// PartyRegistry holds a party's items in every location, its encounters, and the counters its
+// IDs are drawn from. Each party has its own ID namespaces.
+type PartyRegistry struct {
+	Items           map[int]*Item
+	Weapons         map[int]*Weapon
+	Armor           map[int]*Armor
+	Shields         map[int]*Shield
+	Jewelry         map[int]*Jewelry
+	LimitedUseItems map[int]*LimitedUseItem
+	Spellbooks      map[int]*Spellbook
+	Encounters      map[int]*Encounter
+	NextCharacterID int
+	NextItemID      int
+	NextEncounterID int
+}
+
+// HISTORY
+
+// History holds a party's undo and redo stacks, most recent last.
+type History struct {
+	Undo []HistoryEntry
+	Redo []HistoryEntry
+}
+
+// HistoryEntry is one change to a party, kept as JSON merge patches of the party and registries:
+// Redo makes the change and Undo takes it back.
+type HistoryEntry struct {
+	Action string // e.g. "POST /api/characters/3/status"
+	Day    int
+	Turn   int
+	Undo   json.RawMessage
+	Redo   json.RawMessage
 }
 
+// EVENTS
+
+// Event is one entry in the campaign journal.
+type Event struct {
+	Seq          int
+	Type         EventType
+	Time         time.Time
+	CampaignID   int
+	PartyID      int
+	Session      int
+	Day          int
+	Turn         int
+	CharacterIDs []int
+	ItemIDs      []int
+	Message      string          // e.g. "Aldric picked up Sword +1 from limbo"
+	Patch        json.RawMessage // the change to the party and registries, as a JSON merge patch
+	Snapshot     json.RawMessage // the whole party and registries, on snapshot events only
+}
+
+type EventType string
+
+const (
+	EventCharacterAdded   EventType = "character-added"
+	EventCharacterChanged EventType = "character-changed"
+	EventCharacterRemoved EventType = "character-removed"
+	EventStatusChanged    EventType = "status-changed"
+	EventItemMoved        EventType = "item-moved"
+	EventItemEquipped     EventType = "item-equipped"
+	EventItemUsed         EventType = "item-used"
+	EventItemDestroyed    EventType = "item-destroyed"
+	EventSpellMemorized   EventType = "spell-memorized"
+	EventSpellCast        EventType = "spell-cast"
+	EventSpellLearned     EventType = "spell-learned"
+	EventEffect           EventType = "effect"
+	EventEncounter        EventType = "encounter"
+	EventExperience       EventType = "experience"
+	EventTreasure         EventType = "treasure"
+	EventRetainer         EventType = "retainer"
+	EventRest             EventType = "rest"
+	EventClock            EventType = "clock"
+	EventHistory          EventType = "history"
+	EventSession          EventType = "session"
+	EventChanged          EventType = "changed"  // a change no other event describes
+	EventSnapshot         EventType = "snapshot" // a checkpoint to replay the journal from
+)
+
 // CHARACTER
 
 type Character struct {
@@ -20,6 +126,7 @@ type Character struct {
 	Class            CharacterClass
 	Level            int
 	Alignment        Alignment
+	Status           CharacterStatus
 	ArmorBonus       int
 	RolledHitPoints  int
 	CurrentHitPoints int
@@ -30,12 +137,19 @@ type Character struct {
 	Dexterity        int
 	Constitution     int
 	Charisma         int
+	Experience       int
+	Gold             int
 	Items            []int
 	ArmorID          int
 	ShieldID         int
 	Spellcasting     []SpellType
-	KnownSpells      []int // Known spells for Magic Users and Elves. Left empty for Clerics
+	KnownSpells      []int // Known spells for Magic Users and Elves without a spellbook item. Left empty for Clerics
 	MemorizedSpells  []MemorizedSpell
+	SpellbookEntries []SpellbookEntry // Where each copied entry in KnownSpells came from
+	PreparingSpells  bool             // True from a rest until the day's spells are locked in
+	PreparedSpellLog []PreparedSpellList
+	Effects          []ActiveEffect
+	Retainer         *Retainer // nil for player characters
 }
 
 type CharacterClass string
@@ -60,6 +174,62 @@ const (
 	AlignmentChaotic Alignment = "chaotic"
 )
 
+type CharacterStatus string
+
+const (
+	StatusAlive       CharacterStatus = "alive"
+	StatusUnconscious CharacterStatus = "unconscious"
+	StatusDead        CharacterStatus = "dead"
+	StatusPetrified   CharacterStatus = "petrified"
+	StatusParalyzed   CharacterStatus = "paralyzed"
+	StatusPoisoned    CharacterStatus = "poisoned"
+)
+
+// RETAINERS
+
+type Retainer struct {
+	EmployerID     int
+	Wage           int // gold per month
+	Loyalty        int // the retainer's morale, set from the employer's Charisma when hired
+	XPShare        int // percent of a full share of experience; by the book a retainer earns half
+	HiredDay       int
+	PaidThroughDay int // wages are paid up to this day
+	LoyaltyRecord  []LoyaltyEntry
+}
+
+// LoyaltyEntry is one loyalty check or change in a retainer's loyalty.
+type LoyaltyEntry struct {
+	Day     int
+	Reason  string
+	Change  int  // 0 for a check
+	Roll    int  // 0 for a change
+	Loyalty int  // loyalty after the entry
+	Passed  bool // checks only
+}
+
+// EFFECTS
+
+type ActiveEffect struct {
+	ID              int
+	Name            string // e.g. "Bless"
+	Source          string // who or what caused it, e.g. "Mirel's spell" or "Potion of Heroism"
+	ArmorClass      int    // bonus to armor class; positive is better
+	ToHit           int    // bonus to hit
+	Saves           int    // bonus to every saving throw
+	Duration        int    // as given, in Unit
+	Unit            DurationUnit
+	RemainingRounds int // every duration is counted down in rounds
+}
+
+type DurationUnit string
+
+const (
+	DurationRounds DurationUnit = "rounds" // 10 seconds
+	DurationTurns  DurationUnit = "turns"  // 10 minutes
+	DurationHours  DurationUnit = "hours"
+	DurationDays   DurationUnit = "days"
+)
+
 // ITEMS
 
 type Item struct {
@@ -80,6 +250,7 @@ const (
 	ItemShield     ItemType = "shield"
 	ItemJewelry    ItemType = "jewelry"
 	ItemLimitedUse ItemType = "limiteduseitem"
+	ItemSpellbook  ItemType = "spellbook"
 )
 
 type ItemLocation string
@@ -104,8 +275,8 @@ type Weapon struct {
 
 type Armor struct {
 	Item
-	Type  ArmorType
-	Bonus int
+	ArmorType ArmorType
+	Bonus     int
 }
 
 type ArmorType string
@@ -136,15 +307,38 @@ type LimitedUseItem struct {
 	ArcaneAllowed   bool
 	DivineAllowed   bool
 	NonMagicAllowed bool
+	SpellID         int // Spell produced on use (e.g. a Scroll of Fireball). 0 if the item casts no spell
+	Supply          SupplyType
+	Lit             bool // Light sources only
+	TurnsBurned     int  // Turns the current charge of a light source has been burning
+}
+
+// SupplyType marks limited use items that the campaign clock consumes. Each charge is one torch,
+// one flask of oil, or one day's rations.
+type SupplyType string
+
+const (
+	SupplyNone   SupplyType = ""
+	SupplyTorch  SupplyType = "torch"
+	SupplyOil    SupplyType = "oil"
+	SupplyRation SupplyType = "ration"
+)
+
+type Spellbook struct {
+	Item
+	OwnerID int // ID of the character who wrote the book. Only the owner can memorize from it
+	Entries []SpellbookEntry
 }
 
 // SPELLS
 
 type Spell struct {
-	ID    int
-	Name  string
-	Level int
-	Type  SpellType
+	ID           int
+	Name         string
+	Level        int
+	Type         SpellType
+	Reversible   bool
+	ReversedName string // e.g. Cause Light Wounds for Cure Light Wounds
 }
 
 type SpellType string
@@ -155,10 +349,128 @@ const (
 )
 
 type MemorizedSpell struct {
+	SpellID  int
+	Cast     bool
+	Reversed bool // Magic Users memorize the reversed form; Clerics choose it when casting
+}
+
+type PreparedSpellList struct {
+	Day      int
+	SpellIDs []int
+}
+
+type SpellbookEntry struct {
 	SpellID int
-	Cast    bool
+	Source  SpellSource
+}
+
+type SpellSource struct {
+	Type     SpellSourceType
+	ItemID   int    // ID of the scroll or spellbook the spell was copied from
+	ItemName string // Kept so the source is still readable after a spent scroll is thrown away
+}
+
+type SpellSourceType string
+
+const (
+	SourceNone      SpellSourceType = ""
+	SourceScroll    SpellSourceType = "scroll"
+	SourceSpellbook SpellSourceType = "spellbook"
+)
+
+// MONSTERS
+
+type Monster struct {
+	ID           int
+	Name         string
+	ArmorClass   int
+	HitDice      string // As written in the stat block: "1/2", "1-1", "2", "3+1"
+	Attacks      []MonsterAttack
+	Movement     string
+	SavesAs      string // e.g. "F2", or "NH" for normal humans
+	Morale       int
+	TreasureType string
+	Alignment    Alignment
+	XPValue      int
+}
+
+type MonsterAttack struct {
+	Name   string
+	Damage string // Dice expression, e.g. "1d6" or "2d4+1"
+}
+
+// ENCOUNTERS
+
+type Encounter struct {
+	ID           int
+	Name         string
+	Initiative   InitiativeMode
+	Round        int
+	Turn         int   // Index into TurnOrder of the combatant currently acting
+	TurnOrder    []int // Combatant IDs, highest initiative first
+	Combatants   []Combatant
+	Reactions    []ReactionRoll
+	MoraleChecks []MoraleCheck
+	Log          []string
+	Ended        bool
+}
+
+type InitiativeMode string
+
+const (
+	InitiativeSide       InitiativeMode = "side"       // one d6 per side, by the book
+	InitiativeIndividual InitiativeMode = "individual" // one d6 per combatant, adjusted by Dexterity
+)
+
+type Combatant struct {
+	ID               int
+	Name             string
+	Side             CombatSide
+	CharacterID      int // 0 for anything that isn't a party member
+	MonsterID        int // Stat block in MonstersByID, if the combatant was spawned from one
+	ArmorClass       int // Only used when CharacterID is 0; characters derive theirs from equipment
+	CurrentHitPoints int // Only used when CharacterID is 0; characters use their own hit points
+	MaximumHitPoints int
+	Morale           int // 2 to 12. 0 for combatants that never check morale, such as player characters
+	Initiative       int
+	Action           string // Action declared for the current round
+	Defeated         bool
+	Fled             bool // Failed a morale check and is no longer fighting
+}
+
+type ReactionRoll struct {
+	CharacterID int // The character doing the talking, whose Charisma modifies the roll
+	Roll        int
+	Modifier    int
+	Result      Reaction
+}
+
+type Reaction string
+
+const (
+	ReactionHostile     Reaction = "hostile"     // attacks
+	ReactionUnfriendly  Reaction = "unfriendly"  // may attack
+	ReactionNeutral     Reaction = "neutral"     // uncertain
+	ReactionIndifferent Reaction = "indifferent" // uninterested
+	ReactionFriendly    Reaction = "friendly"    // helpful
+)
+
+type MoraleCheck struct {
+	Round   int
+	Side    CombatSide
+	Trigger string // "first death", "half losses", or "referee"
+	Roll    int
+	Morale  int
+	Passed  bool
 }
 
+type CombatSide string
+
+const (
+	SideParty    CombatSide = "party"
+	SideMonsters CombatSide = "monsters"
+)
+
 // REGISTRIES
 
 var ItemsByID = map[int]*Item{}
@@ -167,6 +479,9 @@ var ArmorByID = map[int]*Armor{}
 var ShieldsByID = map[int]*Shield{}
 var JewelryByID = map[int]*Jewelry{}
 var LimitedUseItemsByID = map[int]*LimitedUseItem{}
+var SpellbooksByID = map[int]*Spellbook{}
+var EncountersByID = map[int]*Encounter{}
+var MonstersByID = map[int]*Monster{}
 
 // REGISTRATION
 
@@ -218,6 +533,22 @@ func RegisterLimitedUseItem(lu LimitedUseItem) error {
 	return nil
 }
 
+func RegisterSpellbook(sb Spellbook) error {
+	if _, exists := SpellbooksByID[sb.ID]; exists {
+		return fmt.Errorf("spellbook with ID %d already registered", sb.ID)
+	}
+	SpellbooksByID[sb.ID] = &sb
+	return nil
+}
+
+func RegisterMonster(m Monster) error {
+	if _, exists := MonstersByID[m.ID]; exists {
+		return fmt.Errorf("monster with ID %d already registered", m.ID)
+	}
+	MonstersByID[m.ID] = &m
+	return nil
+}
+
 func UnregisterItem(id int) {
 	delete(ItemsByID, id)
 	delete(WeaponsByID, id)
@@ -225,6 +556,7 @@ func UnregisterItem(id int) {
 	delete(ShieldsByID, id)
 	delete(JewelryByID, id)
 	delete(LimitedUseItemsByID, id)
+	delete(SpellbooksByID, id)
 }
 
 // GETTERS
@@ -252,3 +584,11 @@ func GetJewelryByID(id int) *Jewelry {
 func GetLimitedUseItemByID(id int) *LimitedUseItem {
 	return LimitedUseItemsByID[id]
 }
+
+func GetSpellbookByID(id int) *Spellbook {
+	return SpellbooksByID[id]
+}
+
+func GetMonsterByID(id int) *Monster {
+	return MonstersByID[id]
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // FUNCTIONS
@@ -24,6 +26,7 @@ func AddCharacter(p *Party, name string) Character {
 		Class:            ClassNone,
 		Level:            1,
 		Alignment:        AlignmentNone,
+		Status:           StatusAlive,
 		ArmorBonus:       0,
 		RolledHitPoints:  0,
 		CurrentHitPoints: 0,
@@ -34,33 +37,51 @@ func AddCharacter(p *Party, name string) Character {
 		Dexterity:        3,
 		Constitution:     3,
 		Charisma:         3,
+		Experience:       0,
+		Gold:             0,
 		Items:            []int{},
 		ArmorID:          0,
 		ShieldID:         0,
 		Spellcasting:     []SpellType{},
 		KnownSpells:      []int{},
 		MemorizedSpells:  []MemorizedSpell{},
+		SpellbookEntries: []SpellbookEntry{},
+		PreparedSpellLog: []PreparedSpellList{},
+		Effects:          []ActiveEffect{},
 	}
+	// A new character starts the day ready to prepare spells
+	OpenSpellPreparation(&char, p.Day)
 	p.Characters = append(p.Characters, char)
+	emit(EventCharacterAdded, []int{char.ID}, nil, "%s joined the party", char.Name)
 	return char
 }
 
 // DeleteCharacter moves all of the character's items to Limbo, then removes the character.
+// If any step fails the character and their items are left as they were.
 func DeleteCharacter(charID int, p *Party) error {
-	_, err := FindChar(p, charID)
+	return Transact(p, func() error { return deleteCharacter(charID, p) })
+}
+
+func deleteCharacter(charID int, p *Party) error {
+	ch, err := FindChar(p, charID)
 	if err != nil {
 		return err
 	}
+	if n := len(Retainers(charID, p)); n > 0 {
+		return fmt.Errorf("%s still employs %d retainers", ch.Name, n)
+	}
 
 	// Re-home all items first (clears equips as a side effect).
-	if err := MoveAllFromCharacter(charID, LocationLimbo, p); err != nil {
+	if err := moveAllFromCharacter(charID, LocationLimbo, p); err != nil {
 		return err
 	}
 
 	// Remove character from party slice.
+	name := ch.Name
 	for i := range p.Characters {
 		if p.Characters[i].ID == charID {
 			p.Characters = append(p.Characters[:i], p.Characters[i+1:]...)
+			emit(EventCharacterRemoved, []int{charID}, nil, "%s left the party", name)
 			return nil
 		}
 	}
@@ -69,6 +90,7 @@ func DeleteCharacter(charID int, p *Party) error {
 }
 
 func ApplyCharacterPatch(c *Character, patch CharacterPatch) {
+	defer emitCharacterPatch(c, patch)
 	if patch.Name != nil {
 		c.Name = *patch.Name
 	}
@@ -89,6 +111,7 @@ func ApplyCharacterPatch(c *Character, patch CharacterPatch) {
 	}
 	if patch.CurrentHitPoints != nil {
 		c.CurrentHitPoints = *patch.CurrentHitPoints
+		UpdateCharacterStatus(c)
 	}
 	if patch.MaximumHitPoints != nil {
 		c.MaximumHitPoints = *patch.MaximumHitPoints
@@ -111,6 +134,12 @@ func ApplyCharacterPatch(c *Character, patch CharacterPatch) {
 	if patch.Charisma != nil {
 		c.Charisma = *patch.Charisma
 	}
+	if patch.Experience != nil {
+		c.Experience = *patch.Experience
+	}
+	if patch.Gold != nil {
+		c.Gold = *patch.Gold
+	}
 	if patch.Items != nil {
 		c.Items = *patch.Items
 	}
@@ -131,6 +160,20 @@ func ApplyCharacterPatch(c *Character, patch CharacterPatch) {
 	}
 }
 
+// emitCharacterPatch records which parts of a character sheet a patch changed.
+func emitCharacterPatch(c *Character, patch CharacterPatch) {
+	var changed []string
+	v := reflect.ValueOf(patch)
+	for i := range v.NumField() {
+		if !v.Field(i).IsNil() {
+			changed = append(changed, v.Type().Field(i).Name)
+		}
+	}
+	if len(changed) > 0 {
+		emit(EventCharacterChanged, []int{c.ID}, nil, "%s's character sheet was updated: %s", c.Name, strings.Join(changed, ", "))
+	}
+}
+
 type CharacterPatch struct {
 	Name             *string
 	Class            *CharacterClass
@@ -146,6 +189,8 @@ type CharacterPatch struct {
 	Dexterity        *int
 	Constitution     *int
 	Charisma         *int
+	Experience       *int
+	Gold             *int
 	Items            *[]int
 	ArmorID          *int
 	ShieldID         *int
@@ -196,7 +241,10 @@ func ValidateCharacterPatch(p CharacterPatch) error {
 		return fmt.Errorf("rolled hit points must be greater than 0")
 	}
 	if p.CurrentHitPoints != nil {
-		if *p.CurrentHitPoints < 0 {
+		if *p.CurrentHitPoints < lowestHitPoints() {
+			if DeathRules.DeathsDoor {
+				return fmt.Errorf("current hit points cannot be below %d", lowestHitPoints())
+			}
 			return fmt.Errorf("current hit points cannot be negative")
 		}
 		if p.RolledHitPoints != nil && *p.CurrentHitPoints > *p.RolledHitPoints {
@@ -222,6 +270,12 @@ func ValidateCharacterPatch(p CharacterPatch) error {
 	if p.Charisma != nil && (*p.Charisma < 3 || *p.Charisma > 18) {
 		return fmt.Errorf("charisma must be between 3 and 18")
 	}
+	if p.Experience != nil && *p.Experience < 0 {
+		return fmt.Errorf("experience cannot be negative")
+	}
+	if p.Gold != nil && *p.Gold < 0 {
+		return fmt.Errorf("gold cannot be negative")
+	}
 	// Optional: validate item IDs, spell IDs, etc.
 	return nil
 }
@@ -0,0 +1,97 @@
+package main
+
+// ABILITY SCORES
+
+// AbilityModifier returns the standard B/X modifier for an ability score.
+// It is used for Strength (melee to-hit and damage), Dexterity (AC and missile to-hit),
+// and Constitution (hit points).
+func AbilityModifier(score int) int {
+	switch {
+	case score <= 3:
+		return -3
+	case score <= 5:
+		return -2
+	case score <= 8:
+		return -1
+	case score <= 12:
+		return 0
+	case score <= 15:
+		return 1
+	case score <= 17:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// CharismaReactionModifier returns the B/X Charisma adjustment to reaction rolls.
+func CharismaReactionModifier(cha int) int {
+	switch {
+	case cha <= 3:
+		return -2
+	case cha <= 8:
+		return -1
+	case cha <= 12:
+		return 0
+	case cha <= 17:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// RetainerMorale returns the morale of an employer's retainers, from the employer's Charisma.
+func RetainerMorale(cha int) int {
+	switch {
+	case cha <= 3:
+		return 4
+	case cha <= 5:
+		return 5
+	case cha <= 8:
+		return 6
+	case cha <= 12:
+		return 7
+	case cha <= 15:
+		return 8
+	case cha <= 17:
+		return 9
+	default:
+		return 10
+	}
+}
+
+// MaxRetainers returns how many retainers a character may employ at once, from their Charisma.
+func MaxRetainers(cha int) int {
+	switch {
+	case cha <= 3:
+		return 1
+	case cha <= 5:
+		return 2
+	case cha <= 8:
+		return 3
+	case cha <= 12:
+		return 4
+	case cha <= 15:
+		return 5
+	case cha <= 17:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// DexterityInitiativeModifier returns the B/X Dexterity adjustment to individual initiative.
+func DexterityInitiativeModifier(dex int) int {
+	switch {
+	case dex <= 3:
+		return -2
+	case dex <= 8:
+		return -1
+	case dex <= 12:
+		return 0
+	case dex <= 17:
+		return 1
+	default:
+		return 2
+	}
+}
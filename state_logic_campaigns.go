@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// CAMPAIGNS
+
+var Campaigns = []*Campaign{}
+
+// activeParty is the party whose contents are loaded into State and the registries.
+var activeParty *PartyRecord
+
+var nextCampaignID = 1
+
+func generateUniqueCampaignID() int {
+	id := nextCampaignID
+	nextCampaignID++
+	return id
+}
+
+// InitCampaigns creates the default campaign, whose first party adopts whatever is already in
+// State and the registries. It does nothing if a campaign already exists.
+func InitCampaigns() {
+	if len(Campaigns) > 0 {
+		return
+	}
+	c := NewCampaign("Default Campaign")
+	pr := &PartyRecord{ID: 1, Name: "Party"}
+	c.Parties = append(c.Parties, pr)
+	activeParty = pr
+	stashActiveParty()
+}
+
+// NewCampaign adds an empty campaign.
+func NewCampaign(name string) *Campaign {
+	c := &Campaign{
+		ID:      generateUniqueCampaignID(),
+		Name:    name,
+		Session: 1,
+		Parties: []*PartyRecord{},
+	}
+	Campaigns = append(Campaigns, c)
+	return c
+}
+
+// AddParty adds a new, empty party to a campaign.
+func AddParty(c *Campaign, name string) *PartyRecord {
+	maxID := 0
+	for _, pr := range c.Parties {
+		maxID = max(maxID, pr.ID)
+	}
+	pr := &PartyRecord{
+		ID:       maxID + 1,
+		Name:     name,
+		Party:    Party{Characters: []Character{}},
+		Registry: newPartyRegistry(),
+	}
+	c.Parties = append(c.Parties, pr)
+	return pr
+}
+
+func newPartyRegistry() PartyRegistry {
+	return PartyRegistry{
+		Items:           map[int]*Item{},
+		Weapons:         map[int]*Weapon{},
+		Armor:           map[int]*Armor{},
+		Shields:         map[int]*Shield{},
+		Jewelry:         map[int]*Jewelry{},
+		LimitedUseItems: map[int]*LimitedUseItem{},
+		Spellbooks:      map[int]*Spellbook{},
+		Encounters:      map[int]*Encounter{},
+		NextCharacterID: 1,
+		NextItemID:      1,
+		NextEncounterID: 1,
+	}
+}
+
+func FindCampaign(id int) (*Campaign, error) {
+	for _, c := range Campaigns {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("campaign %d not found", id)
+}
+
+func FindParty(c *Campaign, id int) (*PartyRecord, error) {
+	for _, pr := range c.Parties {
+		if pr.ID == id {
+			return pr, nil
+		}
+	}
+	return nil, fmt.Errorf("party %d not found in %s", id, c.Name)
+}
+
+// campaignOf finds the campaign a party belongs to.
+func campaignOf(pr *PartyRecord) *Campaign {
+	for _, c := range Campaigns {
+		for _, candidate := range c.Parties {
+			if candidate == pr {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultParty is the first party of the first campaign, used by the unscoped API routes.
+func DefaultParty() (*PartyRecord, error) {
+	if len(Campaigns) == 0 || len(Campaigns[0].Parties) == 0 {
+		return nil, fmt.Errorf("no default party")
+	}
+	return Campaigns[0].Parties[0], nil
+}
+
+// ActivateParty saves the active party away and loads pr into State and the registries, so the
+// rest of the game logic works on it.
+func ActivateParty(pr *PartyRecord) {
+	if activeParty == pr {
+		return
+	}
+	stashActiveParty()
+	activeParty = pr
+	reloadActiveParty()
+}
+
+// stashActiveParty copies State and the registries back into the active party's record.
+func stashActiveParty() {
+	if activeParty == nil {
+		return
+	}
+	activeParty.Party = State
+	activeParty.Registry = currentRegistry()
+}
+
+// reloadActiveParty loads the active party's record into State and the registries.
+func reloadActiveParty() {
+	State = activeParty.Party
+	loadRegistry(activeParty.Registry)
+}
+
+// currentRegistry gathers up the registries and ID counters in use.
+func currentRegistry() PartyRegistry {
+	return PartyRegistry{
+		Items:           ItemsByID,
+		Weapons:         WeaponsByID,
+		Armor:           ArmorByID,
+		Shields:         ShieldsByID,
+		Jewelry:         JewelryByID,
+		LimitedUseItems: LimitedUseItemsByID,
+		Spellbooks:      SpellbooksByID,
+		Encounters:      EncountersByID,
+		NextCharacterID: nextCharacterID,
+		NextItemID:      nextItemID,
+		NextEncounterID: nextEncounterID,
+	}
+}
+
+// loadRegistry puts a party's registries and ID counters in place.
+func loadRegistry(r PartyRegistry) {
+	ItemsByID = r.Items
+	WeaponsByID = r.Weapons
+	ArmorByID = r.Armor
+	ShieldsByID = r.Shields
+	JewelryByID = r.Jewelry
+	LimitedUseItemsByID = r.LimitedUseItems
+	SpellbooksByID = r.Spellbooks
+	EncountersByID = r.Encounters
+	nextCharacterID = r.NextCharacterID
+	nextItemID = r.NextItemID
+	nextEncounterID = r.NextEncounterID
+}
+
+// MOVING BETWEEN PARTIES
+
+// MoveCharacterToParty moves a character, their retainers, everything they carry, and the
+// spellbooks they wrote from one party to another. Books kept in the party's pack, storage or limbo
+// stay in the same place in the destination. They get new character and item IDs in the
+// destination party's namespaces.
+// Returns the moved characters as they now appear in the destination.
+func MoveCharacterToParty(charID int, from, to *PartyRecord) ([]Character, error) {
+	if from == to {
+		return nil, fmt.Errorf("character is already in %s", to.Name)
+	}
+	stashActiveParty()
+	defer reloadActiveParty()
+
+	ch, err := FindChar(&from.Party, charID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.Retainer != nil {
+		if employer, err := FindChar(&from.Party, ch.Retainer.EmployerID); err == nil {
+			return nil, fmt.Errorf("%s works for %s; move their employer instead", ch.Name, employer.Name)
+		}
+	}
+	name := ch.Name
+	group := []int{charID}
+	for _, r := range Retainers(charID, &from.Party) {
+		group = append(group, r.ID)
+	}
+	for _, enc := range from.Registry.Encounters {
+		if enc.Ended {
+			continue
+		}
+		for _, cb := range enc.Combatants {
+			if hasID(group, cb.CharacterID) {
+				return nil, fmt.Errorf("%s is in encounter %d, which has not ended", cb.Name, enc.ID)
+			}
+		}
+	}
+
+	// Spellbooks they wrote go with them wherever they are kept, unless someone staying has one
+	var books []*Spellbook
+	for _, sb := range from.Registry.Spellbooks {
+		if !hasID(group, sb.OwnerID) {
+			continue
+		}
+		if sb.Location == LocationCharacter && !hasID(group, sb.HolderID) {
+			holder, _ := FindChar(&from.Party, sb.HolderID)
+			if holder != nil {
+				return nil, fmt.Errorf("%s is carrying %s, which has to go with its owner", holder.Name, sb.Name)
+			}
+		}
+		if sb.Location != LocationCharacter {
+			books = append(books, sb)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+
+	// Take the characters out of the source party first so new IDs can be handed out
+	var moving []Character
+	kept := from.Party.Characters[:0]
+	for _, c := range from.Party.Characters {
+		if hasID(group, c.ID) {
+			moving = append(moving, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	from.Party.Characters = kept
+
+	charIDs := map[int]int{}
+	for _, c := range moving {
+		charIDs[c.ID] = to.Registry.NextCharacterID
+		to.Registry.NextCharacterID++
+	}
+	itemIDs := map[int]int{}
+	for i := range moving {
+		c := &moving[i]
+		newCharID := charIDs[c.ID]
+		for j, oldID := range c.Items {
+			newID := to.Registry.NextItemID
+			to.Registry.NextItemID++
+			itemIDs[oldID] = newID
+			moveItemRecord(oldID, newID, newCharID, &from.Registry, &to.Registry)
+			c.Items[j] = newID
+			if sb, ok := to.Registry.Spellbooks[newID]; ok {
+				if newOwner, ok := charIDs[sb.OwnerID]; ok {
+					sb.OwnerID = newOwner
+				}
+			}
+		}
+	}
+	for _, sb := range books {
+		newID := to.Registry.NextItemID
+		to.Registry.NextItemID++
+		itemIDs[sb.ID] = newID
+		moveItemRecord(sb.ID, newID, 0, &from.Registry, &to.Registry)
+		sb.OwnerID = charIDs[sb.OwnerID]
+	}
+	for i := range moving {
+		c := &moving[i]
+		c.ID = charIDs[c.ID]
+		if c.ArmorID != NoItemEquipped {
+			c.ArmorID = itemIDs[c.ArmorID]
+		}
+		if c.ShieldID != NoItemEquipped {
+			c.ShieldID = itemIDs[c.ShieldID]
+		}
+		for j := range c.SpellbookEntries {
+			if newID, ok := itemIDs[c.SpellbookEntries[j].Source.ItemID]; ok {
+				c.SpellbookEntries[j].Source.ItemID = newID
+			}
+		}
+		if c.Retainer != nil {
+			if newID, ok := charIDs[c.Retainer.EmployerID]; ok {
+				c.Retainer.EmployerID = newID
+			}
+		}
+	}
+
+	to.Party.Characters = append(to.Party.Characters, moving...)
+	// Undo patches replace the characters and registries whole, so neither party can undo past the move
+	from.History = History{}
+	to.History = History{}
+	emit(EventCharacterRemoved, group, nil, "%s left for %s", name, to.Name)
+	// The request journals the active party's change; the journal of any other party the move
+	// touched needs a snapshot with the move in it
+	for _, pr := range []*PartyRecord{from, to} {
+		if pr != activeParty {
+			if err := JournalSnapshot(pr); err != nil {
+				log.Printf("warning: %v", err)
+			}
+		}
+	}
+	return moving, nil
+}
+
+// moveItemRecord moves one item from a registry to another under a new ID, whatever its type.
+func moveItemRecord(oldID, newID, holderID int, from, to *PartyRegistry) {
+	rehome := func(it *Item) {
+		it.ID = newID
+		it.HolderID = holderID
+	}
+	if it, ok := from.Items[oldID]; ok {
+		rehome(it)
+		to.Items[newID] = it
+	}
+	if w, ok := from.Weapons[oldID]; ok {
+		rehome(&w.Item)
+		to.Weapons[newID] = w
+	}
+	if a, ok := from.Armor[oldID]; ok {
+		rehome(&a.Item)
+		to.Armor[newID] = a
+	}
+	if s, ok := from.Shields[oldID]; ok {
+		rehome(&s.Item)
+		to.Shields[newID] = s
+	}
+	if j, ok := from.Jewelry[oldID]; ok {
+		rehome(&j.Item)
+		to.Jewelry[newID] = j
+	}
+	if lu, ok := from.LimitedUseItems[oldID]; ok {
+		rehome(&lu.Item)
+		to.LimitedUseItems[newID] = lu
+	}
+	if sb, ok := from.Spellbooks[oldID]; ok {
+		rehome(&sb.Item)
+		to.Spellbooks[newID] = sb
+	}
+	delete(from.Items, oldID)
+	delete(from.Weapons, oldID)
+	delete(from.Armor, oldID)
+	delete(from.Shields, oldID)
+	delete(from.Jewelry, oldID)
+	delete(from.LimitedUseItems, oldID)
+	delete(from.Spellbooks, oldID)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMoveCharacterTakesStoredSpellbooks(t *testing.T) {
+	resetState(t)
+	c, spellID := newMagicUser(t)
+	sb, err := BindSpellbook(c.ID, "Mirel's book", &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveItemToStorage(sb.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	from := activeParty
+	to := AddParty(Campaigns[0], "Second")
+	stashActiveParty()
+
+	moved, err := MoveCharacterToParty(c.ID, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(from.Registry.Spellbooks) != 0 {
+		t.Errorf("spellbook left behind: %+v", from.Registry.Spellbooks)
+	}
+	ActivateParty(to)
+	books := ownedSpellbooks(&moved[0])
+	if len(books) != 1 || books[0].Location != LocationStorage || !spellbookContains(books[0], spellID) {
+		t.Fatalf("moved character's spellbooks are %+v", books)
+	}
+	if !hasID(learnedSpellIDs(&moved[0]), spellID) {
+		t.Error("moved character lost the spell in their stored book")
+	}
+}
+
+func TestMoveCharacterRefusedWhileSomeoneCarriesTheirBook(t *testing.T) {
+	resetState(t)
+	c, _ := newMagicUser(t)
+	sb, err := BindSpellbook(c.ID, "Mirel's book", &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thief := AddCharacter(&State, "Nim")
+	if err := MoveItemToCharacter(sb.ID, thief.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	from := activeParty
+	to := AddParty(Campaigns[0], "Second")
+	stashActiveParty()
+
+	if _, err := MoveCharacterToParty(c.ID, from, to); err == nil {
+		t.Fatal("the move should be refused while another character carries the book")
+	}
+	if len(from.Party.Characters) != 2 || len(to.Party.Characters) != 0 {
+		t.Error("a refused move changed the parties")
+	}
+}
+
+func TestReadingTheActivePartyDoesNotWaitForOtherReads(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties", `{"Name":"Second"}`)
+
+	// Another read of the active party is in progress
+	partyMu.RLock()
+	done := make(chan int)
+	go func() { done <- apiResponse(e, "GET", "/api/clock", "").Code }()
+	select {
+	case code := <-done:
+		if code != 200 {
+			t.Errorf("reading the clock: status %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Error("a read of the active party waited for the party lock")
+	}
+
+	// Reading another party switches the active party, so it has to wait
+	go func() { done <- apiResponse(e, "GET", "/api/campaigns/1/parties/2/clock", "").Code }()
+	select {
+	case <-done:
+		t.Error("switching parties did not wait for the read in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	partyMu.RUnlock()
+	if code := <-done; code != 200 {
+		t.Errorf("reading the second party's clock: status %d", code)
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CLOCK
+
+// ClockConfig holds the exploration rules driven by the campaign clock.
+type ClockConfig struct {
+	WanderingEvery  int // turns between wandering monster checks; 0 turns them off. B/X: every 2 turns
+	WanderingChance int // wandering monsters appear on a d6 roll of this or less. B/X: 1
+	RestEvery       int // the party must spend 1 turn in this many resting. B/X: 6
+	RationsPerDay   int // rations each character eats when a new day begins
+}
+
+var ClockRules = ClockConfig{2, 1, 6, 1}
+
+// turnsPerCharge is how long one charge of a light source burns: a torch for an hour,
+// a flask of oil in a lantern for four.
+var turnsPerCharge = map[SupplyType]int{
+	SupplyTorch: 1 * TurnsPerHour,
+	SupplyOil:   4 * TurnsPerHour,
+}
+
+type ClockEventKind string
+
+const (
+	ClockLight     ClockEventKind = "light"
+	ClockRest      ClockEventKind = "rest"
+	ClockWandering ClockEventKind = "wandering"
+	ClockRations   ClockEventKind = "rations"
+	ClockEffect    ClockEventKind = "effect"
+	ClockDay       ClockEventKind = "day"
+)
+
+// ClockEvent is something that happened as the clock advanced.
+type ClockEvent struct {
+	Day     int
+	Turn    int
+	Kind    ClockEventKind
+	Message string
+}
+
+// ClockReport is the time after the clock advanced and everything that happened on the way.
+type ClockReport struct {
+	Day            int
+	Turn           int
+	Hour           int
+	TurnsSinceRest int
+	RestDue        bool
+	Events         []ClockEvent
+}
+
+// ClockStatus reports the current time without advancing it.
+func ClockStatus(p *Party) ClockReport {
+	return ClockReport{
+		Day:            p.Day,
+		Turn:           p.Turn,
+		Hour:           p.Turn / TurnsPerHour,
+		TurnsSinceRest: p.TurnsSinceRest,
+		RestDue:        restDue(p),
+		Events:         []ClockEvent{},
+	}
+}
+
+// AdvanceClock moves the campaign clock forward by n turns, hours, or days of exploration, one turn
+// at a time. Each turn burns lit light sources and counts down active effects; every
+// WanderingEvery turns a wandering monster check is rolled; and each new day the party eats.
+func AdvanceClock(p *Party, n int, unit DurationUnit) (ClockReport, error) {
+	if n < 1 {
+		return ClockReport{}, fmt.Errorf("clock must advance at least 1 %s", unit)
+	}
+	var turns int
+	switch unit {
+	case DurationTurns:
+		turns = n
+	case DurationHours:
+		turns = n * TurnsPerHour
+	case DurationDays:
+		turns = n * TurnsPerDay
+	default:
+		return ClockReport{}, fmt.Errorf("the clock cannot advance in %q", unit)
+	}
+
+	var events []ClockEvent
+	for range turns {
+		turnEvents, err := advanceClockTurn(p, false)
+		if err != nil {
+			return ClockReport{}, err
+		}
+		events = append(events, turnEvents...)
+	}
+	report := ClockStatus(p)
+	report.Events = append(report.Events, events...)
+	return report, nil
+}
+
+// RestTurn spends one turn resting, as B/X requires once every six turns.
+func RestTurn(p *Party) (ClockReport, error) {
+	events, err := advanceClockTurn(p, true)
+	if err != nil {
+		return ClockReport{}, err
+	}
+	report := ClockStatus(p)
+	report.Events = append(report.Events, events...)
+	return report, nil
+}
+
+// advanceClockTurn runs a single turn of exploration.
+func advanceClockTurn(p *Party, resting bool) ([]ClockEvent, error) {
+	var events []ClockEvent
+	event := func(kind ClockEventKind, format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		events = append(events, ClockEvent{p.Day, p.Turn, kind, msg})
+		if kind != ClockEffect { // expiring effects are journaled by AdvanceEffects
+			emit(EventClock, nil, nil, "%s", msg)
+		}
+	}
+
+	p.Turn++
+	if p.Turn >= TurnsPerDay {
+		p.Turn = 0
+		fed := startNewDay(p)
+		for _, ch := range p.Characters {
+			for _, msg := range fed[ch.ID] {
+				event(ClockRations, "%s", msg)
+			}
+		}
+		event(ClockDay, "Day %d begins", p.Day)
+	}
+
+	if resting {
+		p.TurnsSinceRest = 0
+		event(ClockRest, "The party rests for a turn")
+	} else {
+		p.TurnsSinceRest++
+		if p.TurnsSinceRest == ClockRules.RestEvery {
+			event(ClockRest, "The party has gone %d turns without rest and is exhausted (-1 to hit and damage until it rests)", p.TurnsSinceRest)
+		}
+	}
+
+	for _, msg := range burnLights(p) {
+		event(ClockLight, "%s", msg)
+	}
+
+	expired, err := AdvanceEffects(p, 1, DurationTurns)
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range expired {
+		event(ClockEffect, "%s", msg)
+	}
+
+	if ClockRules.WanderingEvery > 0 && p.Turn%ClockRules.WanderingEvery == 0 {
+		if roll := rollDie(6); roll <= ClockRules.WanderingChance {
+			event(ClockWandering, "Wandering monsters approach (rolled %d)", roll)
+		}
+	}
+	return events, nil
+}
+
+// restDue reports whether the party has explored as long as it can before it must rest.
+func restDue(p *Party) bool {
+	return ClockRules.RestEvery > 0 && p.TurnsSinceRest >= ClockRules.RestEvery-1
+}
+
+// startNewDay advances the day and feeds the party. It returns, by character ID, a message for
+// every time a character went hungry or finished a pack of rations.
+func startNewDay(p *Party) map[int][]string {
+	p.Day++
+	msgs := map[int][]string{}
+	for i := range p.Characters {
+		ch := &p.Characters[i]
+		if IsDead(ch) {
+			continue
+		}
+		for range ClockRules.RationsPerDay {
+			if msg := eatRation(ch, p); msg != "" {
+				msgs[ch.ID] = append(msgs[ch.ID], msg)
+			}
+		}
+	}
+	return msgs
+}
+
+// eatRation uses one ration, preferring the character's own before the party's shared supplies.
+// Empty packs are thrown away.
+func eatRation(c *Character, p *Party) string {
+	var ration *LimitedUseItem
+	for _, lu := range supplies(SupplyRation) {
+		if lu.Location == LocationCharacter && lu.HolderID == c.ID {
+			ration = lu
+			break
+		}
+	}
+	if ration == nil {
+		for _, lu := range supplies(SupplyRation) {
+			if lu.Location == LocationParty {
+				ration = lu
+				break
+			}
+		}
+	}
+	if ration == nil {
+		return fmt.Sprintf("%s has no rations and goes hungry", c.Name)
+	}
+
+	ration.Charges--
+	if ration.Charges > 0 {
+		return ""
+	}
+	_ = DeleteItem(ration.ID, p)
+	return fmt.Sprintf("%s eats the last of %s", c.Name, ration.Name)
+}
+
+// burnLights burns one turn off every lit light source. A torch or flask of oil that burns out
+// goes dark; light the next one to keep going.
+func burnLights(p *Party) []string {
+	var msgs []string
+	for _, lu := range supplies(SupplyTorch, SupplyOil) {
+		if !lu.Lit {
+			continue
+		}
+		lu.TurnsBurned++
+		if lu.TurnsBurned < turnsPerCharge[lu.Supply] {
+			continue
+		}
+		lu.Charges--
+		lu.TurnsBurned = 0
+		lu.Lit = false
+		if lu.Charges > 0 {
+			msgs = append(msgs, fmt.Sprintf("%s burns out (%d left)", lu.Name, lu.Charges))
+			continue
+		}
+		msgs = append(msgs, fmt.Sprintf("%s burns out (none left)", lu.Name))
+		_ = DeleteItem(lu.ID, p)
+	}
+	return msgs
+}
+
+// supplies returns the stocked limited use items of the given supply types, in ID order.
+func supplies(types ...SupplyType) []*LimitedUseItem {
+	var out []*LimitedUseItem
+	for _, lu := range LimitedUseItemsByID {
+		for _, t := range types {
+			if lu.Supply == t && lu.Charges > 0 {
+				out = append(out, lu)
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// LIGHT
+
+// LightSource lights a torch or a flask of oil the character is carrying.
+func LightSource(charID, itemID int, p *Party) error {
+	ch, lu, err := carriedLight(charID, itemID, p)
+	if err != nil {
+		return err
+	}
+	if err := checkCharacterCanAct(ch); err != nil {
+		return err
+	}
+	if lu.Lit {
+		return fmt.Errorf("%s is already lit", lu.Name)
+	}
+	if lu.Charges <= 0 {
+		return fmt.Errorf("%s has no charges remaining", lu.Name)
+	}
+	lu.Lit = true
+	emit(EventItemUsed, []int{charID}, []int{itemID}, "%s lit %s", ch.Name, lu.Name)
+	return nil
+}
+
+// DouseLight puts out a light source. A partly burned charge keeps its remaining time.
+func DouseLight(charID, itemID int, p *Party) error {
+	ch, lu, err := carriedLight(charID, itemID, p)
+	if err != nil {
+		return err
+	}
+	if !lu.Lit {
+		return fmt.Errorf("%s is not lit", lu.Name)
+	}
+	lu.Lit = false
+	emit(EventItemUsed, []int{charID}, []int{itemID}, "%s put out %s", ch.Name, lu.Name)
+	return nil
+}
+
+func carriedLight(charID, itemID int, p *Party) (*Character, *LimitedUseItem, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return nil, nil, err
+	}
+	lu, ok := LimitedUseItemsByID[itemID]
+	if !ok {
+		return nil, nil, fmt.Errorf("limited-use item %d not found", itemID)
+	}
+	if lu.Location != LocationCharacter || lu.HolderID != charID {
+		return nil, nil, fmt.Errorf("item not owned by character")
+	}
+	if _, ok := turnsPerCharge[lu.Supply]; !ok {
+		return nil, nil, fmt.Errorf("%s is not a light source", lu.Name)
+	}
+	return ch, lu, nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+)
+
+// COMBAT
+
+// CombatConfig holds the optional combat rules in use.
+type CombatConfig struct {
+	VariableWeaponDamage bool // weapons roll their own damage die; by the book every weapon deals 1d6
+	AutoMorale           bool // check morale automatically on a side's first death and at half losses
+}
+
+var CombatRules = CombatConfig{false, true}
+
+// Armor class
+
+// baseArmorClass is the armor class granted by each type of armor before any bonuses.
+var baseArmorClass = map[ArmorType]int{
+	Robes:   9,
+	Leather: 7,
+	Chain:   5,
+	Plate:   3,
+}
+
+// CharacterArmorClass derives a character's descending armor class from their equipped armor and
+// shield, Dexterity, carried protective jewelry, any innate ArmorBonus, and active effects.
+func CharacterArmorClass(c *Character) int {
+	ac := 9
+	if armor, ok := ArmorByID[c.ArmorID]; ok && c.ArmorID != NoItemEquipped {
+		ac = baseArmorClass[armor.ArmorType] - armor.Bonus
+	}
+	if shield, ok := ShieldsByID[c.ShieldID]; ok && c.ShieldID != NoItemEquipped {
+		ac -= 1 + shield.Bonus
+	}
+	for _, id := range c.Items {
+		if j, ok := JewelryByID[id]; ok {
+			ac -= j.ArmorBonus
+		}
+	}
+	ac -= AbilityModifier(c.Dexterity)
+	ac -= c.ArmorBonus
+	effectAC, _, _ := effectTotals(c)
+	ac -= effectAC
+	return ac
+}
+
+// Attack matrix
+
+// characterTHAC0 returns the roll a character needs to hit armor class 0, from the B/X attack tables.
+// Dwarves, Elves, and Halflings fight as Fighters; Thieves fight as Clerics.
+func characterTHAC0(class CharacterClass, level int) int {
+	var steps []int // highest level of each row of the attack table
+	switch class {
+	case ClassFighter, ClassDwarf, ClassElf, ClassHalfling:
+		steps = []int{3, 6, 9, 12}
+	case ClassCleric, ClassThief:
+		steps = []int{4, 8, 12}
+	case ClassMagicUser:
+		steps = []int{5, 10}
+	default:
+		return 20 // normal human
+	}
+	thac0s := []int{19, 17, 14, 12, 10}
+	for i, top := range steps {
+		if level <= top {
+			return thac0s[i]
+		}
+	}
+	return thac0s[len(steps)]
+}
+
+// monsterTHAC0 returns the roll a monster needs to hit armor class 0, by hit dice.
+// A monster with a bonus to its hit dice ("2+1") attacks on the next row up.
+func monsterTHAC0(hitDice string) int {
+	hd, err := ParseHitDice(hitDice)
+	if err != nil || hd.Half || (hd.Dice <= 1 && hd.Modifier <= 0) {
+		return 19
+	}
+	level := hd.Dice
+	if hd.Modifier > 0 {
+		level++
+	}
+	switch {
+	case level <= 7:
+		return 20 - level
+	case level <= 9:
+		return 12
+	default:
+		return max(11-(level-10)/2, 5)
+	}
+}
+
+// combatantTHAC0 looks up the attack matrix row for any combatant.
+func combatantTHAC0(cb *Combatant, p *Party) int {
+	if cb.CharacterID != 0 {
+		if ch, err := FindChar(p, cb.CharacterID); err == nil {
+			return characterTHAC0(ch.Class, ch.Level)
+		}
+	}
+	if m, ok := MonstersByID[cb.MonsterID]; ok {
+		return monsterTHAC0(m.HitDice)
+	}
+	return 19 // generic opponents fight as 1 hit die monsters
+}
+
+// CombatantArmorClass returns a combatant's armor class: derived for characters, from the stat block
+// or combatant for everything else.
+func CombatantArmorClass(cb *Combatant, p *Party) int {
+	if cb.CharacterID != 0 {
+		if ch, err := FindChar(p, cb.CharacterID); err == nil {
+			return CharacterArmorClass(ch)
+		}
+	}
+	return cb.ArmorClass
+}
+
+// RollNeededToHit returns the d20 roll needed to hit the target armor class.
+// A natural 20 always hits and a natural 1 always misses, so the result is kept between 2 and 20.
+func RollNeededToHit(thac0, targetAC int) int {
+	return min(max(thac0-targetAC, 2), 20)
+}
+
+// Attacks
+
+// Attack describes one attack. Characters attack with WeaponID (0 for unarmed);
+// monsters attack with the natural attack at MonsterAttack in their stat block.
+type Attack struct {
+	AttackerID    int
+	TargetID      int
+	WeaponID      int
+	MonsterAttack int
+	Missile       bool // for weapons that can be both thrown and used in melee
+}
+
+// AttackResult reports how an attack went.
+type AttackResult struct {
+	Attacker        string
+	Target          string
+	With            string
+	Roll            int
+	Modifier        int
+	Needed          int
+	Hit             bool
+	Damage          int
+	TargetHitPoints int
+	TargetDefeated  bool
+}
+
+// ResolveAttack rolls to-hit against the attack matrix, rolls damage on a hit, and applies it to
+// the target. Everything is written to the encounter log.
+func ResolveAttack(enc *Encounter, a Attack, p *Party) (AttackResult, error) {
+	if enc.Ended {
+		return AttackResult{}, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	attacker, err := FindCombatant(enc, a.AttackerID)
+	if err != nil {
+		return AttackResult{}, err
+	}
+	target, err := FindCombatant(enc, a.TargetID)
+	if err != nil {
+		return AttackResult{}, err
+	}
+	if attacker.Defeated {
+		return AttackResult{}, fmt.Errorf("%s has been defeated", attacker.Name)
+	}
+	if attacker.Fled {
+		return AttackResult{}, fmt.Errorf("%s has fled", attacker.Name)
+	}
+	if target.Defeated {
+		return AttackResult{}, fmt.Errorf("%s has already been defeated", target.Name)
+	}
+
+	var with string
+	var toHit int
+	var damage func() int
+	if attacker.CharacterID != 0 {
+		with, toHit, damage, err = characterAttack(attacker, a, p)
+	} else {
+		with, damage, err = monsterAttack(attacker, a)
+	}
+	if err != nil {
+		return AttackResult{}, err
+	}
+
+	result := AttackResult{
+		Attacker: attacker.Name,
+		Target:   target.Name,
+		With:     with,
+		Roll:     rollDie(20),
+		Modifier: toHit,
+		Needed:   RollNeededToHit(combatantTHAC0(attacker, p), CombatantArmorClass(target, p)),
+	}
+	switch result.Roll {
+	case 20:
+		result.Hit = true
+	case 1:
+		result.Hit = false
+	default:
+		result.Hit = result.Roll+result.Modifier >= result.Needed
+	}
+
+	if !result.Hit {
+		enc.logf("%s attacks %s with %s: rolls %d%+d, needs %d. Miss", attacker.Name, target.Name, with, result.Roll, result.Modifier, result.Needed)
+	} else {
+		result.Damage = damage()
+		enc.logf("%s attacks %s with %s: rolls %d%+d, needs %d. Hit for %d damage", attacker.Name, target.Name, with, result.Roll, result.Modifier, result.Needed, result.Damage)
+		if err := ApplyDamage(enc, target.ID, result.Damage, p); err != nil {
+			return AttackResult{}, err
+		}
+	}
+
+	result.TargetDefeated = target.Defeated
+	result.TargetHitPoints = target.CurrentHitPoints
+	if ch, err := FindChar(p, target.CharacterID); err == nil {
+		result.TargetHitPoints = ch.CurrentHitPoints
+	}
+	return result, nil
+}
+
+// characterAttack works out what a character is attacking with, their to-hit modifier, and how
+// to roll damage. Strength applies to melee to-hit and damage, Dexterity to missile to-hit, and a
+// magic weapon's bonus to both. Active effects add to every attack roll.
+func characterAttack(attacker *Combatant, a Attack, p *Party) (string, int, func() int, error) {
+	ch, err := FindChar(p, attacker.CharacterID)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	_, effectToHit, _ := effectTotals(ch)
+	if a.WeaponID == 0 {
+		str := AbilityModifier(ch.Strength)
+		return "bare hands", str + effectToHit, func() int { return max(rollDie(2)+str, 1) }, nil
+	}
+
+	w, ok := WeaponsByID[a.WeaponID]
+	if !ok {
+		return "", 0, nil, fmt.Errorf("weapon %d not found", a.WeaponID)
+	}
+	if w.Location != LocationCharacter || w.HolderID != ch.ID {
+		return "", 0, nil, fmt.Errorf("%s is not carrying %s", ch.Name, w.Name)
+	}
+
+	missile := a.Missile || (w.IsRanged && !w.IsMelee)
+	if missile && !w.IsRanged {
+		return "", 0, nil, fmt.Errorf("%s cannot be used at range", w.Name)
+	}
+
+	die := 6
+	if CombatRules.VariableWeaponDamage && w.Damage > 0 {
+		die = w.Damage
+	}
+	toHit, dmgBonus := w.Bonus+AbilityModifier(ch.Dexterity), w.Bonus
+	if !missile {
+		toHit = w.Bonus + AbilityModifier(ch.Strength)
+		dmgBonus += AbilityModifier(ch.Strength)
+	}
+	return w.Name, toHit + effectToHit, func() int { return max(rollDie(die)+dmgBonus, 1) }, nil
+}
+
+// monsterAttack picks one of a monster's natural attacks. Generic opponents hit for 1d6.
+func monsterAttack(attacker *Combatant, a Attack) (string, func() int, error) {
+	m, ok := MonstersByID[attacker.MonsterID]
+	if !ok {
+		return "weapon", func() int { return rollDie(6) }, nil
+	}
+	if a.MonsterAttack < 0 || a.MonsterAttack >= len(m.Attacks) {
+		return "", nil, fmt.Errorf("%s has no attack %d", m.Name, a.MonsterAttack)
+	}
+	atk := m.Attacks[a.MonsterAttack]
+	dice, err := ParseDice(atk.Damage)
+	if err != nil {
+		return "", nil, err
+	}
+	return atk.Name, func() int { return max(dice.Roll(), 0) }, nil
+}
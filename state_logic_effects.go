@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+)
+
+// EFFECTS
+
+const (
+	RoundsPerTurn = 60 // a 10 minute turn holds 60 10-second rounds
+	TurnsPerHour  = 6
+	TurnsPerDay   = 144 // 24 hours of 10 minute turns
+)
+
+// durationInRounds converts a duration to rounds.
+func durationInRounds(n int, unit DurationUnit) (int, error) {
+	switch unit {
+	case DurationRounds:
+		return n, nil
+	case DurationTurns:
+		return n * RoundsPerTurn, nil
+	case DurationHours:
+		return n * TurnsPerHour * RoundsPerTurn, nil
+	case DurationDays:
+		return n * TurnsPerDay * RoundsPerTurn, nil
+	default:
+		return 0, fmt.Errorf("invalid duration unit: %q", unit)
+	}
+}
+
+// AddEffect puts a timed effect on a character. The effect's ID is assigned here.
+func AddEffect(charID int, e ActiveEffect, p *Party) (ActiveEffect, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return ActiveEffect{}, err
+	}
+	if e.Name == "" {
+		return ActiveEffect{}, fmt.Errorf("effect needs a name")
+	}
+	if e.Duration < 1 {
+		return ActiveEffect{}, fmt.Errorf("effect must last at least 1 %s", e.Unit)
+	}
+	rounds, err := durationInRounds(e.Duration, e.Unit)
+	if err != nil {
+		return ActiveEffect{}, err
+	}
+
+	maxID := 0
+	for _, existing := range ch.Effects {
+		maxID = max(maxID, existing.ID)
+	}
+	e.ID = maxID + 1
+	e.RemainingRounds = rounds
+	ch.Effects = append(ch.Effects, e)
+	emit(EventEffect, []int{charID}, nil, "%s is under %s for %d %s", ch.Name, e.Name, e.Duration, e.Unit)
+	return e, nil
+}
+
+// RemoveEffect ends an effect early, e.g. when it is dispelled.
+func RemoveEffect(charID, effectID int, p *Party) error {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return err
+	}
+	for i, e := range ch.Effects {
+		if e.ID == effectID {
+			ch.Effects = append(ch.Effects[:i], ch.Effects[i+1:]...)
+			emit(EventEffect, []int{charID}, nil, "%s on %s was ended", e.Name, ch.Name)
+			return nil
+		}
+	}
+	return fmt.Errorf("effect %d not found on %s", effectID, ch.Name)
+}
+
+// AdvanceEffects counts down every effect on every character in the party and removes the ones
+// that run out. It returns a description of each effect that expired.
+func AdvanceEffects(p *Party, n int, unit DurationUnit) ([]string, error) {
+	rounds, err := durationInRounds(n, unit)
+	if err != nil {
+		return nil, err
+	}
+	var expired []string
+	for i := range p.Characters {
+		ch := &p.Characters[i]
+		kept := ch.Effects[:0]
+		for _, e := range ch.Effects {
+			e.RemainingRounds -= rounds
+			if e.RemainingRounds > 0 {
+				kept = append(kept, e)
+				continue
+			}
+			msg := fmt.Sprintf("%s on %s wears off", e.Name, ch.Name)
+			expired = append(expired, msg)
+			emit(EventEffect, []int{ch.ID}, nil, "%s", msg)
+		}
+		ch.Effects = kept
+	}
+	return expired, nil
+}
+
+// effectTotals sums the modifiers from every active effect on a character.
+func effectTotals(c *Character) (armorClass, toHit, saves int) {
+	for _, e := range c.Effects {
+		armorClass += e.ArmorClass
+		toHit += e.ToHit
+		saves += e.Saves
+	}
+	return armorClass, toHit, saves
+}
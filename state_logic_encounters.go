@@ -0,0 +1,393 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ENCOUNTERS
+
+var nextEncounterID = 1
+
+func generateUniqueEncounterID() int {
+	id := nextEncounterID
+	nextEncounterID++
+	return id
+}
+
+// EncounterSummary reports how an encounter ended.
+type EncounterSummary struct {
+	EncounterID int
+	Name        string
+	Rounds      int
+	Defeated    []string
+	Fled        []string
+	Survivors   []CombatantStatus
+	Experience  int // XP earned from defeated monsters
+	Awards      []ExperienceAward
+	Log         []string
+}
+
+type CombatantStatus struct {
+	Name             string
+	Side             CombatSide
+	CurrentHitPoints int
+	MaximumHitPoints int
+}
+
+// StartEncounter opens a new encounter with every living member of the party on one side.
+// Monsters are added afterwards.
+func StartEncounter(name string, mode InitiativeMode, p *Party) (*Encounter, error) {
+	if mode != InitiativeSide && mode != InitiativeIndividual {
+		return nil, fmt.Errorf("invalid initiative mode: %q", mode)
+	}
+	enc := &Encounter{
+		ID:           generateUniqueEncounterID(),
+		Name:         name,
+		Initiative:   mode,
+		Round:        0,
+		TurnOrder:    []int{},
+		Combatants:   []Combatant{},
+		Reactions:    []ReactionRoll{},
+		MoraleChecks: []MoraleCheck{},
+		Log:          []string{},
+	}
+	enc.logf("Encounter %q begins", name)
+	for _, ch := range p.Characters {
+		if IsDead(&ch) {
+			continue
+		}
+		if _, err := AddCharacterToEncounter(enc, ch.ID, p); err != nil {
+			return nil, err
+		}
+	}
+	EncountersByID[enc.ID] = enc
+	return enc, nil
+}
+
+// FindEncounter looks up an encounter by ID
+func FindEncounter(id int) (*Encounter, error) {
+	enc, ok := EncountersByID[id]
+	if !ok {
+		return nil, fmt.Errorf("encounter %d not found", id)
+	}
+	return enc, nil
+}
+
+// FindCombatant looks up a combatant within an encounter
+func FindCombatant(enc *Encounter, id int) (*Combatant, error) {
+	for i := range enc.Combatants {
+		if enc.Combatants[i].ID == id {
+			return &enc.Combatants[i], nil
+		}
+	}
+	return nil, fmt.Errorf("combatant %d not found in encounter %d", id, enc.ID)
+}
+
+// AddCharacterToEncounter adds a party member on the party side.
+func AddCharacterToEncounter(enc *Encounter, charID int, p *Party) (*Combatant, error) {
+	if enc.Ended {
+		return nil, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return nil, err
+	}
+	for _, cb := range enc.Combatants {
+		if cb.CharacterID == charID {
+			return nil, fmt.Errorf("%s is already in the encounter", ch.Name)
+		}
+	}
+	morale := 0 // player characters never check morale
+	if ch.Retainer != nil {
+		morale = ch.Retainer.Loyalty
+	}
+	return enc.addCombatant(Combatant{
+		Name:        ch.Name,
+		Side:        SideParty,
+		CharacterID: charID,
+		Morale:      morale,
+		Defeated:    ch.CurrentHitPoints <= 0 || IsIncapacitated(ch),
+	}), nil
+}
+
+// AddMonsterToEncounter adds a generic opponent with its own hit points and armor class.
+func AddMonsterToEncounter(enc *Encounter, name string, hitPoints, armorClass int) (*Combatant, error) {
+	if enc.Ended {
+		return nil, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	if hitPoints <= 0 {
+		return nil, fmt.Errorf("hit points must be greater than 0")
+	}
+	return enc.addCombatant(Combatant{
+		Name:             name,
+		Side:             SideMonsters,
+		CurrentHitPoints: hitPoints,
+		MaximumHitPoints: hitPoints,
+		ArmorClass:       armorClass,
+	}), nil
+}
+
+func (enc *Encounter) addCombatant(cb Combatant) *Combatant {
+	maxID := 0
+	for _, existing := range enc.Combatants {
+		if existing.ID > maxID {
+			maxID = existing.ID
+		}
+	}
+	cb.ID = maxID + 1
+	enc.Combatants = append(enc.Combatants, cb)
+	enc.logf("%s joins the %s side", cb.Name, cb.Side)
+	return &enc.Combatants[len(enc.Combatants)-1]
+}
+
+// Initiative
+
+// RollInitiative rolls initiative for a new round and sets the turn order.
+// Side initiative rolls one d6 per side; individual initiative rolls a d6 per combatant
+// adjusted by Dexterity. Ties keep the party first.
+func RollInitiative(enc *Encounter, p *Party) error {
+	if enc.Ended {
+		return fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+
+	if enc.Round > 0 {
+		expired, err := AdvanceEffects(p, 1, DurationRounds)
+		if err != nil {
+			return err
+		}
+		for _, msg := range expired {
+			enc.logf("%s", msg)
+		}
+	}
+	enc.Round++
+	enc.Turn = 0
+	enc.logf("Round %d", enc.Round)
+
+	sideRolls := map[CombatSide]int{}
+	if enc.Initiative == InitiativeSide {
+		for _, side := range []CombatSide{SideParty, SideMonsters} {
+			sideRolls[side] = rollDie(6)
+			enc.logf("%s side rolls %d for initiative", side, sideRolls[side])
+		}
+	}
+
+	for i := range enc.Combatants {
+		cb := &enc.Combatants[i]
+		cb.Action = ""
+		if cb.Defeated {
+			continue
+		}
+		if enc.Initiative == InitiativeSide {
+			cb.Initiative = sideRolls[cb.Side]
+			continue
+		}
+		roll := rollDie(6)
+		cb.Initiative = roll + combatantInitiativeModifier(cb, p)
+		enc.logf("%s rolls %d for initiative (%d)", cb.Name, roll, cb.Initiative)
+	}
+
+	enc.TurnOrder = enc.TurnOrder[:0]
+	for _, cb := range enc.Combatants {
+		if !cb.Defeated && !cb.Fled {
+			enc.TurnOrder = append(enc.TurnOrder, cb.ID)
+		}
+	}
+	sort.SliceStable(enc.TurnOrder, func(i, j int) bool {
+		a, _ := FindCombatant(enc, enc.TurnOrder[i])
+		b, _ := FindCombatant(enc, enc.TurnOrder[j])
+		if a.Initiative != b.Initiative {
+			return a.Initiative > b.Initiative
+		}
+		return a.Side == SideParty && b.Side != SideParty
+	})
+	return nil
+}
+
+func combatantInitiativeModifier(cb *Combatant, p *Party) int {
+	if cb.CharacterID == 0 {
+		return 0
+	}
+	ch, err := FindChar(p, cb.CharacterID)
+	if err != nil {
+		return 0
+	}
+	return DexterityInitiativeModifier(ch.Dexterity)
+}
+
+// Turns
+
+// CurrentCombatant returns the combatant whose turn it is, or nil before initiative is rolled.
+func CurrentCombatant(enc *Encounter) *Combatant {
+	if enc.Turn < 0 || enc.Turn >= len(enc.TurnOrder) {
+		return nil
+	}
+	cb, _ := FindCombatant(enc, enc.TurnOrder[enc.Turn])
+	return cb
+}
+
+// DeclareAction records what a combatant intends to do this round.
+func DeclareAction(enc *Encounter, combatantID int, action string) error {
+	if enc.Ended {
+		return fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	cb, err := FindCombatant(enc, combatantID)
+	if err != nil {
+		return err
+	}
+	if cb.Defeated {
+		return fmt.Errorf("%s has been defeated", cb.Name)
+	}
+	cb.Action = action
+	enc.logf("%s declares: %s", cb.Name, action)
+	return nil
+}
+
+// AdvanceTurn passes the turn to the next combatant still standing.
+// When every combatant has acted, a new round begins and initiative is rolled again.
+func AdvanceTurn(enc *Encounter, p *Party) error {
+	if enc.Ended {
+		return fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	if enc.Round == 0 {
+		return RollInitiative(enc, p)
+	}
+	for enc.Turn++; enc.Turn < len(enc.TurnOrder); enc.Turn++ {
+		if cb := CurrentCombatant(enc); cb != nil && !cb.Defeated && !cb.Fled {
+			enc.logf("%s acts", cb.Name)
+			return nil
+		}
+	}
+	return RollInitiative(enc, p)
+}
+
+// Hit points
+
+// ApplyDamage reduces a combatant's hit points. Party members take the damage on their character.
+// A combatant reduced to 0 hit points is defeated.
+func ApplyDamage(enc *Encounter, combatantID, amount int, p *Party) error {
+	if amount < 0 {
+		return fmt.Errorf("damage cannot be negative")
+	}
+	return changeHitPoints(enc, combatantID, -amount, p)
+}
+
+// ApplyHealing restores a combatant's hit points, never past their maximum.
+func ApplyHealing(enc *Encounter, combatantID, amount int, p *Party) error {
+	if amount < 0 {
+		return fmt.Errorf("healing cannot be negative")
+	}
+	return changeHitPoints(enc, combatantID, amount, p)
+}
+
+func changeHitPoints(enc *Encounter, combatantID, delta int, p *Party) error {
+	if enc.Ended {
+		return fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	cb, err := FindCombatant(enc, combatantID)
+	if err != nil {
+		return err
+	}
+
+	var ch *Character
+	current, maximum, lowest := &cb.CurrentHitPoints, cb.MaximumHitPoints, 0
+	if cb.CharacterID != 0 {
+		if ch, err = FindChar(p, cb.CharacterID); err != nil {
+			return err
+		}
+		if IsDead(ch) {
+			return fmt.Errorf("%s is dead", ch.Name)
+		}
+		current, maximum, lowest = &ch.CurrentHitPoints, ch.MaximumHitPoints, lowestHitPoints()
+	}
+
+	before := *current
+	*current = min(max(before+delta, lowest), max(maximum, before))
+	if delta < 0 {
+		enc.logf("%s takes %d damage (%d hp left)", cb.Name, before-*current, *current)
+	} else {
+		enc.logf("%s heals %d (%d hp)", cb.Name, *current-before, *current)
+	}
+
+	wasDefeated := cb.Defeated
+	cb.Defeated = *current <= 0
+	if ch != nil {
+		status := ch.Status
+		UpdateCharacterStatus(ch)
+		if ch.Status != status {
+			enc.logf("%s is %s", ch.Name, ch.Status)
+		}
+		cb.Defeated = IsIncapacitated(ch)
+	}
+	if cb.Defeated && !wasDefeated {
+		enc.logf("%s is defeated", cb.Name)
+		if CombatRules.AutoMorale {
+			checkMoraleTriggers(enc, cb.Side)
+		}
+	}
+	return nil
+}
+
+// EndEncounter closes the encounter, awards the party XP for the monsters it defeated, and summarizes it.
+func EndEncounter(enc *Encounter, p *Party) (EncounterSummary, error) {
+	if enc.Ended {
+		return EncounterSummary{}, fmt.Errorf("encounter %d has already ended", enc.ID)
+	}
+	enc.logf("Encounter %q ends after %d rounds", enc.Name, enc.Round)
+	enc.Ended = true
+
+	xp := DefeatedMonsterXP(enc)
+	awards, err := AwardExperience(p, xp)
+	if err != nil {
+		return EncounterSummary{}, err
+	}
+	if xp > 0 {
+		enc.logf("The party earns %d XP", xp)
+	}
+
+	summary := SummarizeEncounter(enc, p)
+	summary.Awards = awards
+	return summary, nil
+}
+
+// SummarizeEncounter reports the rounds fought, who fell, and who is left standing.
+func SummarizeEncounter(enc *Encounter, p *Party) EncounterSummary {
+	summary := EncounterSummary{
+		EncounterID: enc.ID,
+		Name:        enc.Name,
+		Rounds:      enc.Round,
+		Defeated:    []string{},
+		Fled:        []string{},
+		Survivors:   []CombatantStatus{},
+		Experience:  DefeatedMonsterXP(enc),
+		Awards:      []ExperienceAward{},
+		Log:         enc.Log,
+	}
+	for _, cb := range enc.Combatants {
+		if cb.Defeated {
+			summary.Defeated = append(summary.Defeated, cb.Name)
+			continue
+		}
+		if cb.Fled {
+			summary.Fled = append(summary.Fled, cb.Name)
+		}
+		status := CombatantStatus{
+			Name:             cb.Name,
+			Side:             cb.Side,
+			CurrentHitPoints: cb.CurrentHitPoints,
+			MaximumHitPoints: cb.MaximumHitPoints,
+		}
+		if ch, err := FindChar(p, cb.CharacterID); err == nil {
+			status.CurrentHitPoints = ch.CurrentHitPoints
+			status.MaximumHitPoints = ch.MaximumHitPoints
+		}
+		summary.Survivors = append(summary.Survivors, status)
+	}
+	return summary
+}
+
+func (enc *Encounter) logf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	enc.Log = append(enc.Log, msg)
+	emit(EventEncounter, nil, nil, "%s: %s", enc.Name, msg)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// EVENTS
+
+// JournalPath is the append-only campaign journal, one JSON event per line.
+var JournalPath = "dungeon-party-events.jsonl"
+
+// pendingEvents are events emitted by changes that haven't been committed to the journal yet.
+// The API commits them once a request succeeds; Transact drops the ones from a rolled back change.
+var pendingEvents []Event
+
+var nextEventSeq = 1
+
+// SnapshotEvery is how many events a party's journal runs between snapshots.
+var SnapshotEvery = 200
+
+// sinceSnapshot counts each party's events since its last snapshot in the journal. A party with no
+// count, like a new one, gets a snapshot before its next change.
+var sinceSnapshot = map[*PartyRecord]int{}
+
+// journalIndex is where each event starts in the journal, in Seq order, so reads can seek past the
+// events they don't want instead of decoding the journal from the start.
+var journalIndex []journalEntry
+
+type journalEntry struct {
+	Seq        int
+	CampaignID int
+	PartyID    int
+	Snapshot   bool
+	Offset     int64
+}
+
+// emit records that something happened. characterIDs and itemIDs are what the event is about, for
+// filtering the journal later.
+func emit(t EventType, characterIDs, itemIDs []int, format string, args ...any) {
+	pendingEvents = append(pendingEvents, Event{
+		Type:         t,
+		CharacterIDs: characterIDs,
+		ItemIDs:      itemIDs,
+		Message:      fmt.Sprintf(format, args...),
+	})
+}
+
+// whereIs describes where an item is for an event message: the name of the character holding it,
+// or its location.
+func whereIs(it *Item, p *Party) string {
+	if it.Location == LocationCharacter {
+		if ch, err := FindChar(p, it.HolderID); err == nil {
+			return ch.Name
+		}
+	}
+	return string(it.Location)
+}
+
+// JournalChange attaches the change a request made to the active party, as a merge patch, to its
+// last pending event, so the journal can be replayed (see ReplayParty). before is a snapshot of the
+// party and registries from before the change. A change with no event of its own is journaled as a
+// changed event named by action. If the party needs a snapshot, before is journaled ahead of the
+// change.
+func JournalChange(patch, before json.RawMessage, action string) {
+	if patch == nil {
+		return
+	}
+	if len(pendingEvents) == 0 {
+		emit(EventChanged, nil, nil, "%s", action)
+	}
+	pendingEvents[len(pendingEvents)-1].Patch = patch
+
+	if n, ok := sinceSnapshot[activeParty]; !ok || n >= SnapshotEvery {
+		snapshot := Event{Type: EventSnapshot, Message: "Snapshot of " + activeParty.Name, Snapshot: before}
+		pendingEvents = append([]Event{snapshot}, pendingEvents...)
+		sinceSnapshot[activeParty] = 0
+	}
+	sinceSnapshot[activeParty] += len(pendingEvents)
+}
+
+// DiscardEvents drops events from changes that didn't go through.
+func DiscardEvents() {
+	pendingEvents = nil
+}
+
+// CommitEvents stamps the pending events with the time, the active party, its session, and the
+// game time, then appends them to the journal. It returns the committed events.
+func CommitEvents(p *Party) ([]Event, error) {
+	if len(pendingEvents) == 0 {
+		return nil, nil
+	}
+	events := pendingEvents
+	pendingEvents = nil
+	return events, appendToJournal(events, activeParty, p)
+}
+
+// JournalSnapshot writes a snapshot of a party to the journal straight away. It is for parties
+// changed outside of a request's own journaling: parties loaded from a save that the journal
+// doesn't already end with, and parties a character moves in or out of while another is active.
+// Their records must be up to date.
+func JournalSnapshot(pr *PartyRecord) error {
+	raw, err := json.Marshal(partySnapshot{pr.Party, pr.Registry})
+	if err != nil {
+		return fmt.Errorf("cannot snapshot %s: %w", pr.Name, err)
+	}
+	snapshot := Event{Type: EventSnapshot, Message: "Snapshot of " + pr.Name, Snapshot: raw}
+	if err := appendToJournal([]Event{snapshot}, pr, &pr.Party); err != nil {
+		// Fall back to a snapshot before the party's next change
+		delete(sinceSnapshot, pr)
+		return err
+	}
+	sinceSnapshot[pr] = 0
+	return nil
+}
+
+// SnapshotIfChanged picks up a party's journal where it left off. The party is only snapshotted if
+// it isn't what its journal replays to, as when a save older than the journal is loaded.
+func SnapshotIfChanged(pr *PartyRecord) error {
+	if campaign := campaignOf(pr); campaign != nil {
+		snap, report, err := ReplayParty(campaign.ID, pr.ID, 0, false)
+		if err == nil {
+			if same, err := sameParty(snap, pr); err == nil && same {
+				sinceSnapshot[pr] = report.Events - report.Snapshots
+				return nil
+			}
+		}
+	}
+	return JournalSnapshot(pr)
+}
+
+// sameParty reports whether a replayed party and registries are what a party record holds.
+func sameParty(snap partySnapshot, pr *PartyRecord) (bool, error) {
+	replayed, err := json.Marshal(snap)
+	if err != nil {
+		return false, err
+	}
+	doc, err := decodeJSON(replayed)
+	if err != nil {
+		return false, err
+	}
+	current, err := json.Marshal(partySnapshot{pr.Party, pr.Registry})
+	if err != nil {
+		return false, err
+	}
+	return sameSnapshot(doc, current)
+}
+
+// appendToJournal stamps events with the time, their party, its session, and the game time, and
+// appends them to the journal.
+func appendToJournal(events []Event, pr *PartyRecord, p *Party) error {
+	now := time.Now()
+	campaign := campaignOf(pr)
+	for i := range events {
+		e := &events[i]
+		e.Seq = nextEventSeq
+		nextEventSeq++
+		e.Time = now
+		e.Day = p.Day
+		e.Turn = p.Turn
+		if campaign != nil {
+			e.CampaignID = campaign.ID
+			e.Session = campaign.Session
+		}
+		if pr != nil {
+			e.PartyID = pr.ID
+		}
+		if e.CharacterIDs == nil {
+			e.CharacterIDs = []int{}
+		}
+		if e.ItemIDs == nil {
+			e.ItemIDs = []int{}
+		}
+	}
+
+	f, err := os.OpenFile(JournalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	for _, e := range events {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("cannot write journal: %w", err)
+		}
+		if _, err := f.Write(append(line, '\n')); err != nil {
+			return fmt.Errorf("cannot write journal: %w", err)
+		}
+		indexEvent(e, offset)
+		offset += int64(len(line)) + 1
+	}
+	return nil
+}
+
+func indexEvent(e Event, offset int64) {
+	journalIndex = append(journalIndex, journalEntry{e.Seq, e.CampaignID, e.PartyID, e.Type == EventSnapshot, offset})
+}
+
+// journalOffset is where the first event after afterSeq starts in the journal. It is false if
+// there is no such event.
+func journalOffset(afterSeq int) (int64, bool) {
+	i := sort.Search(len(journalIndex), func(i int) bool { return journalIndex[i].Seq > afterSeq })
+	if i == len(journalIndex) {
+		return 0, len(journalIndex) == 0
+	}
+	return journalIndex[i].Offset, true
+}
+
+// snapshotSeq finds one of a party's snapshots in the journal: the first, or else the last at or
+// before untilSeq (0 for the end of the journal). It is 0 if the party has none.
+func snapshotSeq(campaignID, partyID, untilSeq int, first bool) int {
+	seq := 0
+	for _, entry := range journalIndex {
+		if untilSeq > 0 && entry.Seq > untilSeq {
+			break
+		}
+		if entry.Snapshot && entry.CampaignID == campaignID && entry.PartyID == partyID {
+			seq = entry.Seq
+			if first {
+				break
+			}
+		}
+	}
+	return seq
+}
+
+// EventFilter selects journal events. Zero values match everything.
+type EventFilter struct {
+	CampaignID  int
+	PartyID     int
+	Session     int
+	CharacterID int
+	ItemID      int
+	Type        EventType
+	AfterSeq    int
+}
+
+func (f EventFilter) matches(e Event) bool {
+	switch {
+	case f.CampaignID != 0 && e.CampaignID != f.CampaignID:
+		return false
+	case f.PartyID != 0 && e.PartyID != f.PartyID:
+		return false
+	case f.Session != 0 && e.Session != f.Session:
+		return false
+	case f.CharacterID != 0 && !hasID(e.CharacterIDs, f.CharacterID):
+		return false
+	case f.ItemID != 0 && !hasID(e.ItemIDs, f.ItemID):
+		return false
+	case f.Type != "" && e.Type != f.Type:
+		return false
+	case e.Seq <= f.AfterSeq:
+		return false
+	}
+	return true
+}
+
+// ReadJournal returns the journal events that match the filter, oldest first. Reading starts at
+// the first event after filter.AfterSeq.
+func ReadJournal(filter EventFilter) ([]Event, error) {
+	events := []Event{}
+	from, ok := journalOffset(filter.AfterSeq)
+	if !ok {
+		return events, nil
+	}
+	err := scanJournal(from, func(e Event, _ int64) {
+		if filter.matches(e) {
+			events = append(events, e)
+		}
+	})
+	return events, err
+}
+
+// LoadJournal picks up the event sequence from an existing journal so new events continue it, and
+// indexes where each event starts.
+func LoadJournal() error {
+	journalIndex = nil
+	return scanJournal(0, func(e Event, offset int64) {
+		nextEventSeq = max(nextEventSeq, e.Seq+1)
+		indexEvent(e, offset)
+	})
+}
+
+// scanJournal decodes the journal from a byte offset, passing each event and its offset to fn.
+func scanJournal(from int64, fn func(e Event, offset int64)) error {
+	f, err := os.Open(JournalPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot read journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Seek(from, io.SeekStart); err != nil {
+		return fmt.Errorf("cannot read journal: %w", err)
+	}
+
+	// Snapshot events hold a whole party, so events are decoded one by one rather than read as
+	// lines of a limited length
+	dec := json.NewDecoder(f)
+	for {
+		offset := from + dec.InputOffset()
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("journal event at byte %d: %w", offset, err)
+		}
+		fn(e, offset)
+	}
+}
+
+// SESSIONS
+
+// StartSession begins a new play session in the active party's campaign.
+func StartSession() (int, error) {
+	campaign := campaignOf(activeParty)
+	if campaign == nil {
+		return 0, fmt.Errorf("no active campaign")
+	}
+	campaign.Session++
+	emit(EventSession, nil, nil, "Session %d of %s begins", campaign.Session, campaign.Name)
+	return campaign.Session, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestJournalReadsEventsLargerThanALine(t *testing.T) {
+	resetState(t)
+	for i := 0; i < 3000; i++ {
+		NewGenericItem(strings.Repeat("Rope of many knots ", 20), LocationStorage)
+	}
+	before, _ := takeSnapshot(&State)
+	if len(before) < 1024*1024 {
+		t.Fatalf("snapshot is only %d bytes; the test needs one over a megabyte", len(before))
+	}
+	AddCharacter(&State, "Aldric")
+	after, _ := takeSnapshot(&State)
+	change, err := diffSnapshots(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	JournalChange(change, before, "test")
+	if _, err := CommitEvents(&State); err != nil {
+		t.Fatal(err)
+	}
+
+	want := nextEventSeq
+	nextEventSeq = 1
+	if err := LoadJournal(); err != nil {
+		t.Fatalf("LoadJournal: %v", err)
+	}
+	if nextEventSeq != want {
+		t.Errorf("journal picked up at %d, want %d", nextEventSeq, want)
+	}
+}
+
+func TestReadingTheJournalSeeksPastEarlierEvents(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Mirel"}`)
+	last := nextEventSeq - 1
+	if err := LoadJournal(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Spoil the first event; a read that starts after it never decodes it
+	data, err := os.ReadFile(JournalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	end := bytes.IndexByte(data, '\n')
+	copy(data[:end], bytes.Repeat([]byte("x"), end))
+	if err := os.WriteFile(JournalPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := ReadJournal(EventFilter{AfterSeq: last - 1})
+	if err != nil {
+		t.Fatalf("reading the last event: %v", err)
+	}
+	if len(events) != 1 || events[0].Seq != last || !strings.Contains(events[0].Message, "Mirel") {
+		t.Errorf("read %+v, want only the event for Mirel", events)
+	}
+	if _, err := ReadJournal(EventFilter{}); err == nil {
+		t.Error("reading the whole journal should reach the spoiled event")
+	}
+}
+
+func TestLoadingOnlySnapshotsChangedParties(t *testing.T) {
+	resetState(t)
+	SavePath = filepath.Join(t.TempDir(), "save.json")
+	e := echo.New()
+	registerAPIRoutes(e)
+	snapshots := func() int {
+		events, err := ReadJournal(EventFilter{Type: EventSnapshot})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(events)
+	}
+
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/save", "")
+	start := snapshots()
+	apiRequest(t, e, "POST", "/api/load", "")
+	apiRequest(t, e, "POST", "/api/load", "")
+	if got := snapshots(); got != start {
+		t.Errorf("loading an unchanged party wrote %d snapshots", got-start)
+	}
+
+	// After Mirel joins, the save no longer matches the journal
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Mirel"}`)
+	apiRequest(t, e, "POST", "/api/load", "")
+	if got := snapshots(); got != start+1 {
+		t.Errorf("loading a save older than the journal wrote %d snapshots, want 1", got-start)
+	}
+	if len(State.Characters) != 1 {
+		t.Errorf("loaded %d characters, want Aldric alone", len(State.Characters))
+	}
+}
+
+func TestStreamedEventsCarryOnlyMessages(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/api/events/stream?after=0", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		e.ServeHTTP(rec, req)
+		close(done)
+	}()
+	// Give the stream time to catch up and subscribe, then send it a live event
+	time.Sleep(50 * time.Millisecond)
+	stateMu.Lock()
+	emit(EventChanged, nil, nil, "Mirel arrives")
+	events, err := CommitEvents(&State)
+	stateMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events[0].Patch = []byte(`{"Day":1}`)
+	publishEvents(events)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	for _, want := range []string{"Aldric", "Mirel arrives"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream is missing %q:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"event: snapshot", `"Patch":{`, `"Snapshot":{`} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("stream has %s:\n%s", unwanted, body)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+)
+
+// EXPERIENCE
+
+// ExperienceAward reports one character's share of an XP award.
+type ExperienceAward struct {
+	CharacterID int
+	Name        string
+	Experience  int
+}
+
+// AwardExperience splits XP between every living character in the party. Player characters get a
+// full share and retainers get their XPShare of one, half by default.
+// Any remainder that doesn't divide evenly is lost, as in the book.
+func AwardExperience(p *Party, xp int) ([]ExperienceAward, error) {
+	if xp < 0 {
+		return nil, fmt.Errorf("experience cannot be negative")
+	}
+	var living []*Character
+	for i := range p.Characters {
+		if !IsDead(&p.Characters[i]) {
+			living = append(living, &p.Characters[i])
+		}
+	}
+	awards := []ExperienceAward{}
+	if len(living) == 0 {
+		return awards, nil
+	}
+
+	totalShares := 0
+	for _, ch := range living {
+		totalShares += experienceShare(ch)
+	}
+	if totalShares == 0 {
+		return awards, nil
+	}
+	for _, ch := range living {
+		share := xp * experienceShare(ch) / totalShares
+		ch.Experience += share
+		awards = append(awards, ExperienceAward{CharacterID: ch.ID, Name: ch.Name, Experience: share})
+		emit(EventExperience, []int{ch.ID}, nil, "%s earned %d experience", ch.Name, share)
+	}
+	return awards, nil
+}
+
+// experienceShare is a character's share of an award, in percent of a full share.
+func experienceShare(c *Character) int {
+	if c.Retainer != nil {
+		return c.Retainer.XPShare
+	}
+	return 100
+}
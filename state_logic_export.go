@@ -0,0 +1,405 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// EXPORT AND IMPORT
+
+// CharacterDocumentFormat and CharacterDocumentVersion identify an exported character.
+const (
+	CharacterDocumentFormat  = "dungeon-party/character"
+	CharacterDocumentVersion = 1
+)
+
+// CharacterDocument is a character packed up to move between campaigns or share as a pregen.
+// It holds everything the character refers to: every item they carry, with its full details, and
+// every spell they know, have memorized, or carry on an item.
+type CharacterDocument struct {
+	Format    string
+	Version   int
+	Character Character
+	Items     []ExportedItem
+	Spells    []Spell
+}
+
+// ExportedItem is one carried item. Exactly one of the fields is set, for the item's type.
+type ExportedItem struct {
+	Item           *Item
+	Weapon         *Weapon
+	Armor          *Armor
+	Shield         *Shield
+	Jewelry        *Jewelry
+	LimitedUseItem *LimitedUseItem
+	Spellbook      *Spellbook
+}
+
+// ExportCharacter packs up a character with their items and spells.
+func ExportCharacter(charID int, p *Party) (CharacterDocument, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return CharacterDocument{}, err
+	}
+	doc := CharacterDocument{
+		Format:    CharacterDocumentFormat,
+		Version:   CharacterDocumentVersion,
+		Character: *ch,
+		Items:     []ExportedItem{},
+		Spells:    []Spell{},
+	}
+	// Retainers work for someone in this party, so they leave as free agents
+	doc.Character.Retainer = nil
+
+	spellIDs := map[int]bool{}
+	for _, id := range ch.Items {
+		var item ExportedItem
+		if it, ok := ItemsByID[id]; ok {
+			copied := *it
+			item.Item = &copied
+		} else if w, ok := WeaponsByID[id]; ok {
+			copied := *w
+			item.Weapon = &copied
+		} else if a, ok := ArmorByID[id]; ok {
+			copied := *a
+			item.Armor = &copied
+		} else if s, ok := ShieldsByID[id]; ok {
+			copied := *s
+			item.Shield = &copied
+		} else if j, ok := JewelryByID[id]; ok {
+			copied := *j
+			item.Jewelry = &copied
+		} else if lu, ok := LimitedUseItemsByID[id]; ok {
+			copied := *lu
+			item.LimitedUseItem = &copied
+			if lu.SpellID != 0 {
+				spellIDs[lu.SpellID] = true
+			}
+		} else if sb, ok := SpellbooksByID[id]; ok {
+			copied := *sb
+			copied.Entries = append([]SpellbookEntry{}, sb.Entries...)
+			item.Spellbook = &copied
+			for _, e := range sb.Entries {
+				spellIDs[e.SpellID] = true
+			}
+		} else {
+			return CharacterDocument{}, fmt.Errorf("%s carries item %d, which does not exist", ch.Name, id)
+		}
+		doc.Items = append(doc.Items, item)
+	}
+
+	for _, id := range ch.KnownSpells {
+		spellIDs[id] = true
+	}
+	for _, ms := range ch.MemorizedSpells {
+		spellIDs[ms.SpellID] = true
+	}
+	for _, e := range ch.SpellbookEntries {
+		spellIDs[e.SpellID] = true
+	}
+	for _, list := range ch.PreparedSpellLog {
+		for _, id := range list.SpellIDs {
+			spellIDs[id] = true
+		}
+	}
+	for id := range spellIDs {
+		if spell, ok := SpellsByID[id]; ok {
+			doc.Spells = append(doc.Spells, spell)
+		}
+	}
+	sort.Slice(doc.Spells, func(i, j int) bool { return doc.Spells[i].ID < doc.Spells[j].ID })
+	return doc, nil
+}
+
+// ImportCharacter adds an exported character to the party. The character and their items are
+// given fresh IDs, and their spells are matched to the spell list by name. The result is validated
+// like any other character; if anything is wrong nothing is added.
+func ImportCharacter(doc CharacterDocument, p *Party) (*Character, error) {
+	if doc.Format != CharacterDocumentFormat {
+		return nil, fmt.Errorf("not an exported character")
+	}
+	if doc.Version < 1 || doc.Version > CharacterDocumentVersion {
+		return nil, fmt.Errorf("cannot import version %d of an exported character", doc.Version)
+	}
+	var imported *Character
+	err := Transact(p, func() error {
+		ch, err := importCharacter(doc, p)
+		imported = ch
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+func importCharacter(doc CharacterDocument, p *Party) (*Character, error) {
+	spellIDs, err := matchSpells(doc.Spells)
+	if err != nil {
+		return nil, err
+	}
+	spell := func(id int) (int, error) {
+		if newID, ok := spellIDs[id]; ok {
+			return newID, nil
+		}
+		return 0, fmt.Errorf("spell %d is not included in the exported character", id)
+	}
+
+	ch := doc.Character
+	oldCharID := ch.ID
+	ch.ID = generateUniqueCharacterID()
+	ch.Retainer = nil
+	if ch.Name == "" {
+		return nil, fmt.Errorf("exported character has no name")
+	}
+
+	// Items first, so the character's references to them can be remapped
+	itemIDs := map[int]int{}
+	for _, item := range doc.Items {
+		newID := generateUniqueItemID()
+		if err := registerImportedItem(item, newID, ch.ID, oldCharID, spell); err != nil {
+			return nil, err
+		}
+		itemIDs[itemBase(item).ID] = newID
+	}
+	remapItem := func(id int) (int, error) {
+		if id == NoItemEquipped {
+			return NoItemEquipped, nil
+		}
+		if newID, ok := itemIDs[id]; ok {
+			return newID, nil
+		}
+		return 0, fmt.Errorf("item %d is not included in the exported character", id)
+	}
+
+	items := make([]int, 0, len(ch.Items))
+	for _, id := range ch.Items {
+		newID, err := remapItem(id)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, newID)
+	}
+	ch.Items = items
+	if ch.ArmorID, err = remapItem(ch.ArmorID); err != nil {
+		return nil, err
+	}
+	if ch.ShieldID, err = remapItem(ch.ShieldID); err != nil {
+		return nil, err
+	}
+
+	known := make([]int, 0, len(ch.KnownSpells))
+	for _, id := range ch.KnownSpells {
+		newID, err := spell(id)
+		if err != nil {
+			return nil, err
+		}
+		known = append(known, newID)
+	}
+	ch.KnownSpells = known
+	memorized := make([]MemorizedSpell, 0, len(ch.MemorizedSpells))
+	for _, ms := range ch.MemorizedSpells {
+		if ms.SpellID, err = spell(ms.SpellID); err != nil {
+			return nil, err
+		}
+		memorized = append(memorized, ms)
+	}
+	ch.MemorizedSpells = memorized
+	if ch.SpellbookEntries, err = remapSpellbookEntries(ch.SpellbookEntries, spell, itemIDs); err != nil {
+		return nil, err
+	}
+	prepared := make([]PreparedSpellList, 0, len(ch.PreparedSpellLog))
+	for _, list := range ch.PreparedSpellLog {
+		ids := make([]int, 0, len(list.SpellIDs))
+		for _, id := range list.SpellIDs {
+			newID, err := spell(id)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, newID)
+		}
+		prepared = append(prepared, PreparedSpellList{Day: list.Day, SpellIDs: ids})
+	}
+	ch.PreparedSpellLog = prepared
+	if ch.Spellcasting == nil {
+		ch.Spellcasting = []SpellType{}
+	}
+	if ch.Effects == nil {
+		ch.Effects = []ActiveEffect{}
+	}
+
+	if err := validateImportedCharacter(&ch); err != nil {
+		return nil, err
+	}
+	p.Characters = append(p.Characters, ch)
+	emit(EventCharacterAdded, []int{ch.ID}, ch.Items, "%s joined the party from an exported character", ch.Name)
+	return &p.Characters[len(p.Characters)-1], nil
+}
+
+// matchSpells maps the spells in an exported character to the spell list: by ID when the spell
+// there has the same name, otherwise by name.
+func matchSpells(spells []Spell) (map[int]int, error) {
+	ids := map[int]int{}
+	for _, s := range spells {
+		if known, ok := SpellsByID[s.ID]; ok && known.Name == s.Name {
+			ids[s.ID] = s.ID
+			continue
+		}
+		found := false
+		for id, known := range SpellsByID {
+			if known.Name == s.Name && known.Type == s.Type {
+				ids[s.ID] = id
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown spell %q", s.Name)
+		}
+	}
+	return ids, nil
+}
+
+// itemBase is the item an exported item holds, whatever its type.
+func itemBase(item ExportedItem) *Item {
+	switch {
+	case item.Item != nil:
+		return item.Item
+	case item.Weapon != nil:
+		return &item.Weapon.Item
+	case item.Armor != nil:
+		return &item.Armor.Item
+	case item.Shield != nil:
+		return &item.Shield.Item
+	case item.Jewelry != nil:
+		return &item.Jewelry.Item
+	case item.LimitedUseItem != nil:
+		return &item.LimitedUseItem.Item
+	case item.Spellbook != nil:
+		return &item.Spellbook.Item
+	}
+	return nil
+}
+
+// registerImportedItem registers a copy of an exported item under a new ID, carried by the
+// imported character.
+func registerImportedItem(item ExportedItem, newID, charID, oldCharID int, spell func(int) (int, error)) error {
+	base := itemBase(item)
+	if base == nil {
+		return fmt.Errorf("exported item has no details")
+	}
+	// The registry decides the item's type, not the document, so nothing can pass as armor it isn't
+	rehome := func(it Item, t ItemType) Item {
+		it.ID = newID
+		it.Type = t
+		it.Location = LocationCharacter
+		it.HolderID = charID
+		return it
+	}
+	switch {
+	case item.Item != nil:
+		return RegisterItem(rehome(*item.Item, ItemGeneric))
+	case item.Weapon != nil:
+		w := *item.Weapon
+		w.Item = rehome(w.Item, ItemWeapon)
+		return RegisterWeapon(w)
+	case item.Armor != nil:
+		a := *item.Armor
+		upgradeArmor(&a)
+		a.Item = rehome(a.Item, ItemArmor)
+		return RegisterArmor(a)
+	case item.Shield != nil:
+		s := *item.Shield
+		s.Item = rehome(s.Item, ItemShield)
+		return RegisterShield(s)
+	case item.Jewelry != nil:
+		j := *item.Jewelry
+		j.Item = rehome(j.Item, ItemJewelry)
+		return RegisterJewelry(j)
+	case item.LimitedUseItem != nil:
+		lu := *item.LimitedUseItem
+		lu.Item = rehome(lu.Item, ItemLimitedUse)
+		if lu.SpellID != 0 {
+			id, err := spell(lu.SpellID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", lu.Name, err)
+			}
+			lu.SpellID = id
+		}
+		return RegisterLimitedUseItem(lu)
+	default:
+		sb := *item.Spellbook
+		sb.Item = rehome(sb.Item, ItemSpellbook)
+		// A book written by someone who isn't coming along has no owner here
+		if sb.OwnerID == oldCharID {
+			sb.OwnerID = charID
+		} else {
+			sb.OwnerID = 0
+		}
+		entries, err := remapSpellbookEntries(sb.Entries, spell, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sb.Name, err)
+		}
+		sb.Entries = entries
+		return RegisterSpellbook(sb)
+	}
+}
+
+// remapSpellbookEntries points spellbook entries at the imported spells, and at the imported
+// items they were copied from if those came along.
+func remapSpellbookEntries(entries []SpellbookEntry, spell func(int) (int, error), itemIDs map[int]int) ([]SpellbookEntry, error) {
+	out := make([]SpellbookEntry, 0, len(entries))
+	for _, e := range entries {
+		id, err := spell(e.SpellID)
+		if err != nil {
+			return nil, err
+		}
+		e.SpellID = id
+		if newID, ok := itemIDs[e.Source.ItemID]; ok {
+			e.Source.ItemID = newID
+		} else {
+			e.Source.ItemID = 0 // the scroll or book stayed behind; ItemName still says what it was
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// validateImportedCharacter checks an imported character the way the rules check any other.
+func validateImportedCharacter(ch *Character) error {
+	patch := CharacterPatch{
+		Name:             &ch.Name,
+		Level:            &ch.Level,
+		Alignment:        &ch.Alignment,
+		CurrentHitPoints: &ch.CurrentHitPoints,
+		Strength:         &ch.Strength,
+		Intelligence:     &ch.Intelligence,
+		Wisdom:           &ch.Wisdom,
+		Dexterity:        &ch.Dexterity,
+		Constitution:     &ch.Constitution,
+		Charisma:         &ch.Charisma,
+		Experience:       &ch.Experience,
+		Gold:             &ch.Gold,
+	}
+	if ch.Class != ClassNone {
+		patch.Class = &ch.Class
+	}
+	if ch.Alignment == AlignmentNone {
+		patch.Alignment = nil
+	}
+	if ch.RolledHitPoints != 0 {
+		patch.RolledHitPoints = &ch.RolledHitPoints
+	}
+	if err := ValidateCharacterPatch(patch); err != nil {
+		return fmt.Errorf("%s: %w", ch.Name, err)
+	}
+	if err := ValidateCharacterInventory(ch); err != nil {
+		return fmt.Errorf("%s: %w", ch.Name, err)
+	}
+	errs := append(ValidateSpells(ch), ValidateMemorizedSpells(ch)...)
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", ch.Name, errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestImportCharacterMovesToAnotherParty(t *testing.T) {
+	resetState(t)
+	ch, armorID, _, _ := outfitFighter(t)
+	doc, err := ExportCharacter(ch.ID, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := json.Marshal(doc)
+
+	ActivateParty(AddParty(Campaigns[0], "Second"))
+	AddCharacter(&State, "Already here")
+	NewGenericItem("Rope", LocationParty)
+	var back CharacterDocument
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportCharacter(back, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The rope took item 1 in this party, so the imported items had to be given new IDs
+	if imported.ID != 2 || len(imported.Items) != 2 || hasID(imported.Items, armorID) {
+		t.Errorf("imported character kept old IDs: %+v", imported)
+	}
+	if w, ok := WeaponsByID[imported.Items[1]]; !ok || w.Name != "Sword" || w.HolderID != imported.ID {
+		t.Errorf("imported sword is %+v", w)
+	}
+	armor, err := FindItemByID(imported.ArmorID)
+	if err != nil || armor.Type != ItemArmor || armor.HolderID != imported.ID {
+		t.Errorf("imported armor is %+v (%v)", armor, err)
+	}
+}
+
+func TestImportCharacterIgnoresDocumentItemTypes(t *testing.T) {
+	resetState(t)
+	ch, _, swordID, _ := outfitFighter(t)
+	doc, err := ExportCharacter(ch.ID, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A stick that claims to be armor, worn as armor
+	for _, item := range doc.Items {
+		if item.Weapon != nil {
+			item.Weapon.Type = ItemArmor
+		}
+	}
+	doc.Character.ArmorID = swordID
+	if _, err := ImportCharacter(doc, &State); err == nil {
+		t.Fatal("a weapon passed off as armor should be rejected")
+	}
+
+	// Without a type at all, the weapon is still a weapon
+	for _, item := range doc.Items {
+		if item.Weapon != nil {
+			item.Weapon.Type = ""
+		}
+	}
+	doc.Character.ArmorID = NoItemEquipped
+	imported, err := ImportCharacter(doc, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range imported.Items {
+		if w, ok := WeaponsByID[id]; ok && w.Type != ItemWeapon {
+			t.Errorf("imported weapon has type %q", w.Type)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// HISTORY
+
+// HistoryLimit is how many changes each party can undo.
+var HistoryLimit = 50
+
+// RecordHistory adds a change to a party's history and clears anything that could be redone.
+// before and after are the party and registries from either side of it, and change is the merge
+// patch between them.
+func RecordHistory(h *History, action string, before, after, change json.RawMessage, p *Party) error {
+	if change == nil {
+		return nil
+	}
+	undo, err := diffSnapshots(after, before)
+	if err != nil {
+		return err
+	}
+	h.Undo = append(h.Undo, HistoryEntry{
+		Action: action,
+		Day:    p.Day,
+		Turn:   p.Turn,
+		Undo:   undo,
+		Redo:   change,
+	})
+	if len(h.Undo) > HistoryLimit {
+		h.Undo = h.Undo[len(h.Undo)-HistoryLimit:]
+	}
+	h.Redo = nil
+	return nil
+}
+
+// Undo puts the party back as it was before its most recent change.
+func Undo(h *History, p *Party) (HistoryEntry, error) {
+	if len(h.Undo) == 0 {
+		return HistoryEntry{}, fmt.Errorf("nothing to undo")
+	}
+	entry := h.Undo[len(h.Undo)-1]
+	if err := patchParty(p, entry.Undo); err != nil {
+		return HistoryEntry{}, fmt.Errorf("cannot undo %s: %w", entry.Action, err)
+	}
+	h.Undo = h.Undo[:len(h.Undo)-1]
+	h.Redo = append(h.Redo, entry)
+	emit(EventHistory, nil, nil, "Undid %s", entry.Action)
+	return entry, nil
+}
+
+// Redo applies the most recently undone change again.
+func Redo(h *History, p *Party) (HistoryEntry, error) {
+	if len(h.Redo) == 0 {
+		return HistoryEntry{}, fmt.Errorf("nothing to redo")
+	}
+	entry := h.Redo[len(h.Redo)-1]
+	if err := patchParty(p, entry.Redo); err != nil {
+		return HistoryEntry{}, fmt.Errorf("cannot redo %s: %w", entry.Action, err)
+	}
+	h.Redo = h.Redo[:len(h.Redo)-1]
+	h.Undo = append(h.Undo, entry)
+	emit(EventHistory, nil, nil, "Redid %s", entry.Action)
+	return entry, nil
+}
+
+// patchParty applies a merge patch to the party and registries.
+func patchParty(p *Party, patch json.RawMessage) error {
+	if !isJSONValue(patch) {
+		return fmt.Errorf("the change was not recorded")
+	}
+	current, err := takeSnapshot(p)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSON(current)
+	if err != nil {
+		return err
+	}
+	change, err := decodeJSON(patch)
+	if err != nil {
+		return err
+	}
+	patched, err := json.Marshal(applyMergePatch(doc, change))
+	if err != nil {
+		return err
+	}
+	return restoreSnapshot(p, patched)
+}
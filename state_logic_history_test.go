@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestUndoAndRedoThroughTheAPI(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Mirel"}`)
+	h := activeParty.History
+	if len(h.Undo) != 2 {
+		t.Fatalf("history holds %d changes, want 2", len(h.Undo))
+	}
+	if !isJSONValue(h.Undo[1].Undo) || !isJSONValue(h.Undo[1].Redo) {
+		t.Fatal("history entry is missing its patches")
+	}
+
+	apiRequest(t, e, "POST", "/api/history/undo", "")
+	if len(State.Characters) != 1 || State.Characters[0].Name != "Aldric" {
+		t.Fatalf("after undo the party is %+v, want only Aldric", State.Characters)
+	}
+	apiRequest(t, e, "POST", "/api/history/redo", "")
+	if len(State.Characters) != 2 || State.Characters[1].Name != "Mirel" {
+		t.Fatalf("after redo the party is %+v, want Aldric and Mirel", State.Characters)
+	}
+}
+
+func TestMovingACharacterClearsBothHistories(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties", `{"Name":"Second"}`)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties/2/characters", `{"Name":"Nim"}`)
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties/1/characters/1/move", `{"PartyID":2}`)
+
+	// Undoing Aldric's arrival in either party would bring back a list of characters from before the move
+	for _, path := range []string{"/api/history/undo", "/api/campaigns/1/parties/2/history/undo"} {
+		if rec := apiResponse(e, "POST", path, ""); rec.Code != 400 {
+			t.Errorf("%s after a move: status %d, want 400", path, rec.Code)
+		}
+	}
+	// The last request left the second party active
+	if len(State.Characters) != 2 {
+		t.Errorf("second party has %d characters, want Nim and Aldric", len(State.Characters))
+	}
+	if first := Campaigns[0].Parties[0]; len(first.Party.Characters) != 0 {
+		t.Errorf("first party still has %+v", first.Party.Characters)
+	}
+}
+
+func TestUndoAfterLoadingASave(t *testing.T) {
+	resetState(t)
+	SavePath = filepath.Join(t.TempDir(), "save.json")
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/save", "")
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Mirel"}`)
+	apiRequest(t, e, "POST", "/api/load", "")
+
+	// The history comes back as it was saved, with Aldric's arrival and nothing after it
+	apiRequest(t, e, "POST", "/api/history/undo", "")
+	if len(State.Characters) != 0 {
+		t.Errorf("after undo the party is %+v, want no one", State.Characters)
+	}
+	if rec := apiResponse(e, "POST", "/api/history/undo", ""); rec.Code != 400 {
+		t.Errorf("a second undo: status %d, want 400", rec.Code)
+	}
+}
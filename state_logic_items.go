@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 )
 
@@ -40,9 +41,40 @@ func FindItemByID(id int) (*Item, error) {
 	if lu, ok := LimitedUseItemsByID[id]; ok {
 		return &lu.Item, nil
 	}
+	if sb, ok := SpellbooksByID[id]; ok {
+		return &sb.Item, nil
+	}
 	return nil, fmt.Errorf("item %d not found", id)
 }
 
+// AllItems gathers every item in every registry, in ID order
+func AllItems() []*Item {
+	var out []*Item
+	for _, it := range ItemsByID {
+		out = append(out, it)
+	}
+	for _, w := range WeaponsByID {
+		out = append(out, &w.Item)
+	}
+	for _, a := range ArmorByID {
+		out = append(out, &a.Item)
+	}
+	for _, s := range ShieldsByID {
+		out = append(out, &s.Item)
+	}
+	for _, j := range JewelryByID {
+		out = append(out, &j.Item)
+	}
+	for _, lu := range LimitedUseItemsByID {
+		out = append(out, &lu.Item)
+	}
+	for _, sb := range SpellbooksByID {
+		out = append(out, &sb.Item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
 func DetachItemFromCharacter(p *Party, itemID int) {
 	it, err := FindItemByID(itemID)
 	if err != nil {
@@ -93,14 +125,14 @@ func ValidateCharacterInventory(c *Character) error {
 	}
 	// equip checks
 	if c.ArmorID != NoItemEquipped {
-		it := ItemsByID[c.ArmorID]
-		if !hasID(c.Items, c.ArmorID) || it.Type != ItemArmor {
+		it, err := FindItemByID(c.ArmorID)
+		if err != nil || !hasID(c.Items, c.ArmorID) || it.Type != ItemArmor {
 			return fmt.Errorf("armor id invalid or not armor")
 		}
 	}
 	if c.ShieldID != NoItemEquipped {
-		it := ItemsByID[c.ShieldID]
-		if !hasID(c.Items, c.ShieldID) || it.Type != ItemShield {
+		it, err := FindItemByID(c.ShieldID)
+		if err != nil || !hasID(c.Items, c.ShieldID) || it.Type != ItemShield {
 			return fmt.Errorf("shield id invalid or not shield")
 		}
 	}
@@ -108,7 +140,12 @@ func ValidateCharacterInventory(c *Character) error {
 }
 
 // MoveItemToCharacter Moves (do all checks first, then mutate)
+// The move is undone if the character's inventory fails validation afterwards.
 func MoveItemToCharacter(itemID, charID int, p *Party) error {
+	return Transact(p, func() error { return moveItemToCharacter(itemID, charID, p) })
+}
+
+func moveItemToCharacter(itemID, charID int, p *Party) error {
 	it, err := FindItemByID(itemID)
 	if err != nil {
 		return err
@@ -123,6 +160,8 @@ func MoveItemToCharacter(itemID, charID int, p *Party) error {
 	if len(ch.Items) >= 10 {
 		return fmt.Errorf("inventory full")
 	}
+	from := whereIs(it, p)
+	fromID := it.HolderID
 
 	// Detach if coming from another character
 	if it.Location == LocationCharacter && it.HolderID != charID {
@@ -137,9 +176,26 @@ func MoveItemToCharacter(itemID, charID int, p *Party) error {
 	if err := ValidateCharacterInventory(ch); err != nil {
 		return fmt.Errorf("post-move validation failed: %w", err)
 	}
+	chars := []int{charID}
+	if fromID != 0 && fromID != charID {
+		chars = append(chars, fromID)
+	}
+	emit(EventItemMoved, chars, []int{itemID}, "%s picked up %s from %s", ch.Name, it.Name, from)
 	return nil
 }
 
+// MoveItemsToCharacter hands a character several items at once. Either every item is moved or none are.
+func MoveItemsToCharacter(itemIDs []int, charID int, p *Party) error {
+	return Transact(p, func() error {
+		for _, id := range itemIDs {
+			if err := moveItemToCharacter(id, charID, p); err != nil {
+				return fmt.Errorf("item %d: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
 func MoveItemToParty(itemID int, p *Party) error { return MoveItemToBucket(itemID, LocationParty, p) }
 func MoveItemToStorage(itemID int, p *Party) error {
 	return MoveItemToBucket(itemID, LocationStorage, p)
@@ -152,6 +208,12 @@ func MoveItemToBucket(itemID int, loc ItemLocation, p *Party) error {
 		return err
 	}
 
+	from := whereIs(it, p)
+	var chars []int
+	if it.Location == LocationCharacter {
+		chars = []int{it.HolderID}
+	}
+
 	// Detach if held by a character
 	if it.Location == LocationCharacter {
 		DetachItemFromCharacter(p, itemID)
@@ -159,6 +221,7 @@ func MoveItemToBucket(itemID int, loc ItemLocation, p *Party) error {
 
 	it.Location = loc
 	it.HolderID = 0
+	emit(EventItemMoved, chars, []int{itemID}, "%s moved from %s to %s", it.Name, from, loc)
 	return nil
 }
 
@@ -197,11 +260,12 @@ func EquipArmor(charID, itemID int, p *Party) error {
 	}
 	// if a class has no armor restrictions, the following check is skipped
 	if allowed, ok := allowedArmorTypes[ch.Class]; ok {
-		if !allowed[armor.Type] {
-			return fmt.Errorf("%s cannot wear %s", ch.Class, armor.Type)
+		if !allowed[armor.ArmorType] {
+			return fmt.Errorf("%s cannot wear %s", ch.Class, armor.ArmorType)
 		}
 	}
 	ch.ArmorID = itemID
+	emit(EventItemEquipped, []int{charID}, []int{itemID}, "%s put on %s", ch.Name, it.Name)
 	return nil
 }
 
@@ -231,6 +295,7 @@ func EquipShield(charID, itemID int, p *Party) error {
 		return fmt.Errorf("%s cannot equip shield", ch.Class)
 	}
 	ch.ShieldID = itemID
+	emit(EventItemEquipped, []int{charID}, []int{itemID}, "%s readied %s", ch.Name, it.Name)
 	return nil
 }
 
@@ -243,6 +308,7 @@ func UnequipArmor(charID int, p *Party) error {
 	if ch.ArmorID == NoItemEquipped {
 		return nil // nothing equipped; no-op
 	}
+	emit(EventItemEquipped, []int{charID}, []int{ch.ArmorID}, "%s took off their armor", ch.Name)
 	ch.ArmorID = NoItemEquipped
 	return nil
 }
@@ -256,6 +322,7 @@ func UnequipShield(charID int, p *Party) error {
 	if ch.ShieldID == NoItemEquipped {
 		return nil // nothing equipped; no-op
 	}
+	emit(EventItemEquipped, []int{charID}, []int{ch.ShieldID}, "%s put away their shield", ch.Name)
 	ch.ShieldID = NoItemEquipped
 	return nil
 }
@@ -263,8 +330,12 @@ func UnequipShield(charID int, p *Party) error {
 // MoveAllFromCharacter moves every item from the given character to the specified bucket location.
 // Allowed targets: LocationParty, LocationStorage, LocationLimbo.
 // It clears ArmorID/ShieldID if those items are moved.
-// TODO: Make MoveAllFromCharacter atomic
+// Either every item is moved or none are.
 func MoveAllFromCharacter(charID int, to ItemLocation, p *Party) error {
+	return Transact(p, func() error { return moveAllFromCharacter(charID, to, p) })
+}
+
+func moveAllFromCharacter(charID int, to ItemLocation, p *Party) error {
 	if to == LocationCharacter || to == LocationNone {
 		return fmt.Errorf("invalid target location for bulk move: %v", to)
 	}
@@ -310,6 +381,8 @@ func DeleteItem(itemID int, p *Party) error {
 		DetachItemFromCharacter(p, itemID)
 	}
 
+	emit(EventItemDestroyed, nil, []int{itemID}, "%s is gone", it.Name)
+
 	// Remove from all global registries
 	delete(ItemsByID, itemID)
 	delete(WeaponsByID, itemID)
@@ -317,6 +390,7 @@ func DeleteItem(itemID int, p *Party) error {
 	delete(ShieldsByID, itemID)
 	delete(JewelryByID, itemID)
 	delete(LimitedUseItemsByID, itemID)
+	delete(SpellbooksByID, itemID)
 
 	return nil
 }
@@ -378,9 +452,9 @@ func NewArmor(
 	loc ItemLocation,
 ) *Armor {
 	a := &Armor{
-		Item:  newBaseItem(name, ItemArmor, loc),
-		Type:  armorType,
-		Bonus: bonus,
+		Item:      newBaseItem(name, ItemArmor, loc),
+		ArmorType: armorType,
+		Bonus:     bonus,
 	}
 	ArmorByID[a.ID] = a
 	return a
@@ -424,6 +498,18 @@ func NewLimitedUseItem(
 	return lu
 }
 
+// NewSpellbook creates an empty spellbook. Entries are added by copying spells into it.
+// ownerID is 0 for books that belong to no character in the party, such as captured ones.
+func NewSpellbook(name string, ownerID int, loc ItemLocation) *Spellbook {
+	sb := &Spellbook{
+		Item:    newBaseItem(name, ItemSpellbook, loc),
+		OwnerID: ownerID,
+		Entries: []SpellbookEntry{},
+	}
+	SpellbooksByID[sb.ID] = sb
+	return sb
+}
+
 // Edit Items
 
 // EditGenericItem updates an existing generic item by ID.
@@ -508,7 +594,7 @@ func EditArmor(id int, armorType ArmorType, bonus int, p *Party) error {
 		}
 	}
 
-	armor.Type = armorType
+	armor.ArmorType = armorType
 	armor.Bonus = bonus
 
 	return nil
@@ -560,6 +646,126 @@ func EditLimitedUseItem(id int, charges int, arcane, divine, nonmagic bool) erro
 	return nil
 }
 
+// LinkLimitedUseItemSpell ties a limited use item to a spell so using it resolves like casting that spell.
+// Pass 0 to remove the link.
+func LinkLimitedUseItemSpell(id, spellID int) error {
+	lu, ok := LimitedUseItemsByID[id]
+	if !ok {
+		return fmt.Errorf("limited-use item %d not found", id)
+	}
+	if spellID != 0 {
+		if _, err := getSpellIfExists(spellID); err != nil {
+			return err
+		}
+	}
+	lu.SpellID = spellID
+	return nil
+}
+
+// SetLimitedUseItemSupply marks a limited use item as torches, lantern oil, or rations so the campaign
+// clock will consume it. Pass SupplyNone to clear it.
+func SetLimitedUseItemSupply(id int, supply SupplyType) error {
+	lu, ok := LimitedUseItemsByID[id]
+	if !ok {
+		return fmt.Errorf("limited-use item %d not found", id)
+	}
+	switch supply {
+	case SupplyNone, SupplyTorch, SupplyOil, SupplyRation:
+	default:
+		return fmt.Errorf("invalid supply type: %q", supply)
+	}
+	lu.Supply = supply
+	if supply != SupplyTorch && supply != SupplyOil {
+		lu.Lit = false
+		lu.TurnsBurned = 0
+	}
+	return nil
+}
+
+// Use Items
+
+// checkIfCharacterMayUse applies the class gating flags of a limited use item.
+// NonMagicAllowed items may be used by anyone; otherwise the character must cast a permitted type of magic.
+func checkIfCharacterMayUse(c *Character, lu *LimitedUseItem) error {
+	if lu.NonMagicAllowed {
+		return nil
+	}
+	for _, st := range c.Spellcasting {
+		if st == SpellArcane && lu.ArcaneAllowed {
+			return nil
+		}
+		if st == SpellDivine && lu.DivineAllowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s cannot use %s", c.Class, lu.Name)
+}
+
+// UseLimitedUseItem spends one charge of a limited use item carried by a character.
+// If the item is linked to a spell, that spell is returned so it can be resolved as if it were cast.
+// Items are kept at 0 charges; removing a spent item is left to the caller.
+func UseLimitedUseItem(charID, itemID int, p *Party) (*Spell, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return nil, err
+	}
+	lu, ok := LimitedUseItemsByID[itemID]
+	if !ok {
+		return nil, fmt.Errorf("limited-use item %d not found", itemID)
+	}
+	if lu.Location != LocationCharacter || lu.HolderID != charID {
+		return nil, fmt.Errorf("item not owned by character")
+	}
+	if err := checkCharacterCanAct(ch); err != nil {
+		return nil, err
+	}
+	if err := checkIfCharacterMayUse(ch, lu); err != nil {
+		return nil, err
+	}
+	if lu.Charges <= 0 {
+		return nil, fmt.Errorf("%s has no charges remaining", lu.Name)
+	}
+
+	var spell *Spell
+	if lu.SpellID != 0 {
+		s, err := getSpellIfExists(lu.SpellID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", lu.Name, err)
+		}
+		spell = &s
+	}
+
+	lu.Charges--
+	if spell != nil {
+		emit(EventItemUsed, []int{charID}, []int{itemID}, "%s used %s, casting %s", ch.Name, lu.Name, spell.Name)
+	} else {
+		emit(EventItemUsed, []int{charID}, []int{itemID}, "%s used %s", ch.Name, lu.Name)
+	}
+	return spell, nil
+}
+
+// EditSpellbook replaces the contents of an existing spellbook by ID.
+// Used by the referee to fill in captured spellbooks; the entries have no recorded source.
+func EditSpellbook(id int, spellIDs []int) error {
+	sb, ok := SpellbooksByID[id]
+	if !ok {
+		return fmt.Errorf("spellbook %d not found", id)
+	}
+	entries := make([]SpellbookEntry, 0, len(spellIDs))
+	for _, spellID := range spellIDs {
+		spell, err := getSpellIfExists(spellID)
+		if err != nil {
+			return err
+		}
+		if spell.Type != SpellArcane {
+			return fmt.Errorf("%s is not an arcane spell", spell.Name)
+		}
+		entries = append(entries, SpellbookEntry{SpellID: spellID})
+	}
+	sb.Entries = entries
+	return nil
+}
+
 // DumpInventory returns a human-readable string listing all items a character holds.
 // Marks equipped armor and shield.
 func DumpInventory(charID int, p *Party) (string, error) {
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestUsingALimitedUseItemThroughTheAPI(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	c, spellID := newMagicUser(t)
+	wand := NewLimitedUseItem("Wand of Sleep", 1, true, false, false, LocationStorage)
+	wand.SpellID = spellID
+	if err := MoveItemToCharacter(wand.ID, c.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+
+	path := fmt.Sprintf("/api/characters/%d/items/%d/use", c.ID, wand.ID)
+	apiRequest(t, e, "POST", path, "")
+	if wand.Charges != 0 {
+		t.Errorf("wand has %d charges after use, want 0", wand.Charges)
+	}
+	if _, ok := LimitedUseItemsByID[wand.ID]; !ok {
+		t.Error("a spent item should be kept")
+	}
+	if rec := apiResponse(e, "POST", path, ""); rec.Code != 400 {
+		t.Errorf("using a spent wand: status %d, want 400", rec.Code)
+	}
+}
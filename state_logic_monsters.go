@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MONSTERS
+
+// HitDice is a parsed monster hit dice value such as "3+1".
+type HitDice struct {
+	Dice     int
+	Modifier int
+	Half     bool // "1/2": the monster has 1d4 hit points
+}
+
+// ParseHitDice parses hit dice as written in a stat block: "1/2", "1-1", "2", "3+1".
+func ParseHitDice(hd string) (HitDice, error) {
+	s := strings.TrimSpace(hd)
+	if s == "1/2" {
+		return HitDice{Half: true}, nil
+	}
+
+	var h HitDice
+	dicePart := s
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		m, err := strconv.Atoi(s[i:])
+		if err != nil {
+			return HitDice{}, fmt.Errorf("invalid hit dice %q", hd)
+		}
+		dicePart, h.Modifier = s[:i], m
+	}
+	n, err := strconv.Atoi(dicePart)
+	if err != nil || n < 1 {
+		return HitDice{}, fmt.Errorf("invalid hit dice %q", hd)
+	}
+	h.Dice = n
+	return h, nil
+}
+
+// RollMonsterHitPoints rolls hit points for one monster: a d8 per hit die plus the modifier,
+// or 1d4 for half a hit die. Every monster has at least 1 hit point.
+func RollMonsterHitPoints(m *Monster) (int, error) {
+	hd, err := ParseHitDice(m.HitDice)
+	if err != nil {
+		return 0, err
+	}
+	if hd.Half {
+		return rollDie(4), nil
+	}
+	return max(RollDice(hd.Dice, 8)+hd.Modifier, 1), nil
+}
+
+// SpawnMonsters adds count monsters from the catalog to an encounter, each with rolled hit points.
+// Monsters are numbered after those of the same kind already in the encounter: "Goblin 1",
+// "Goblin 2", ... Every hit point roll is made before any monster joins, so a failure adds none.
+func SpawnMonsters(enc *Encounter, monsterID, count int) ([]Combatant, error) {
+	if enc.Ended {
+		return nil, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	m, ok := MonstersByID[monsterID]
+	if !ok {
+		return nil, fmt.Errorf("monster %d not found", monsterID)
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("must spawn at least 1 %s", m.Name)
+	}
+
+	hps := make([]int, count)
+	for i := range hps {
+		hp, err := RollMonsterHitPoints(m)
+		if err != nil {
+			return nil, err
+		}
+		hps[i] = hp
+	}
+	existing := 0
+	for _, cb := range enc.Combatants {
+		if cb.MonsterID == m.ID {
+			existing++
+		}
+	}
+
+	spawned := make([]Combatant, 0, count)
+	for i, hp := range hps {
+		cb := enc.addCombatant(Combatant{
+			Name:             fmt.Sprintf("%s %d", m.Name, existing+i+1),
+			Side:             SideMonsters,
+			MonsterID:        m.ID,
+			ArmorClass:       m.ArmorClass,
+			Morale:           m.Morale,
+			CurrentHitPoints: hp,
+			MaximumHitPoints: hp,
+		})
+		spawned = append(spawned, *cb)
+	}
+	return spawned, nil
+}
+
+// DefeatedMonsterXP totals the XP value of every catalog monster defeated in an encounter.
+func DefeatedMonsterXP(enc *Encounter) int {
+	total := 0
+	for _, cb := range enc.Combatants {
+		if !cb.Defeated || cb.MonsterID == 0 {
+			continue
+		}
+		if m, ok := MonstersByID[cb.MonsterID]; ok {
+			total += m.XPValue
+		}
+	}
+	return total
+}
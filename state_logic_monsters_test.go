@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSpawnedMonstersAreNumberedByKind(t *testing.T) {
+	resetState(t)
+	MonstersByID[9001] = &Monster{ID: 9001, Name: "Goblin", HitDice: "1-1"}
+	MonstersByID[9002] = &Monster{ID: 9002, Name: "Ogre", HitDice: "four"}
+	t.Cleanup(func() {
+		delete(MonstersByID, 9001)
+		delete(MonstersByID, 9002)
+	})
+	enc, err := StartEncounter("Ambush", InitiativeSide, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, count := range []int{1, 2} {
+		spawned, err := SpawnMonsters(enc, 9001, count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cb := range spawned {
+			names = append(names, cb.Name)
+		}
+	}
+	want := []string{"Goblin 1", "Goblin 2", "Goblin 3"}
+	if !slices.Equal(names, want) {
+		t.Errorf("spawned %v, want %v", names, want)
+	}
+
+	before := len(enc.Combatants)
+	if _, err := SpawnMonsters(enc, 9002, 3); err == nil {
+		t.Fatal("spawning a monster with unreadable hit dice should fail")
+	}
+	if len(enc.Combatants) != before {
+		t.Errorf("a failed spawn added %d combatants", len(enc.Combatants)-before)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+)
+
+// REACTIONS
+
+// reactionFor reads the B/X reaction table for a modified 2d6 roll.
+func reactionFor(total int) Reaction {
+	switch {
+	case total <= 2:
+		return ReactionHostile
+	case total <= 5:
+		return ReactionUnfriendly
+	case total <= 8:
+		return ReactionNeutral
+	case total <= 11:
+		return ReactionIndifferent
+	default:
+		return ReactionFriendly
+	}
+}
+
+// RollReaction rolls 2d6 for how the monsters react to the party, modified by the Charisma of the
+// character doing the talking. Pass charID 0 for an unmodified roll.
+func RollReaction(enc *Encounter, charID int, p *Party) (ReactionRoll, error) {
+	if enc.Ended {
+		return ReactionRoll{}, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	r := ReactionRoll{CharacterID: charID}
+	speaker := "the party"
+	if charID != 0 {
+		ch, err := FindChar(p, charID)
+		if err != nil {
+			return ReactionRoll{}, err
+		}
+		r.Modifier = CharismaReactionModifier(ch.Charisma)
+		speaker = ch.Name
+	}
+	r.Roll = RollDice(2, 6)
+	r.Result = reactionFor(r.Roll + r.Modifier)
+
+	enc.Reactions = append(enc.Reactions, r)
+	enc.logf("Reaction to %s: rolls %d%+d, %s", speaker, r.Roll, r.Modifier, r.Result)
+	return r, nil
+}
+
+// MORALE
+
+// CheckMorale rolls 2d6 against a side's morale. If the roll is higher, everyone still standing
+// on that side flees. A morale of 12 never breaks and a side with no morale score never checks.
+func CheckMorale(enc *Encounter, side CombatSide, trigger string) (MoraleCheck, error) {
+	if enc.Ended {
+		return MoraleCheck{}, fmt.Errorf("encounter %d has ended", enc.ID)
+	}
+	morale := sideMorale(enc, side)
+	if morale == 0 {
+		return MoraleCheck{}, fmt.Errorf("the %s side does not check morale", side)
+	}
+
+	mc := MoraleCheck{
+		Round:   enc.Round,
+		Side:    side,
+		Trigger: trigger,
+		Roll:    RollDice(2, 6),
+		Morale:  morale,
+	}
+	mc.Passed = morale >= 12 || mc.Roll <= morale
+	enc.MoraleChecks = append(enc.MoraleChecks, mc)
+
+	if mc.Passed {
+		enc.logf("The %s side checks morale (%s): rolls %d against %d and stands firm", side, trigger, mc.Roll, morale)
+		return mc, nil
+	}
+	enc.logf("The %s side checks morale (%s): rolls %d against %d and flees", side, trigger, mc.Roll, morale)
+	for i := range enc.Combatants {
+		cb := &enc.Combatants[i]
+		if cb.Side == side && !cb.Defeated && cb.Morale > 0 {
+			cb.Fled = true
+		}
+	}
+	return mc, nil
+}
+
+// sideMorale returns the best morale among a side's combatants that are still fighting.
+func sideMorale(enc *Encounter, side CombatSide) int {
+	morale := 0
+	for _, cb := range enc.Combatants {
+		if cb.Side == side && !cb.Defeated && !cb.Fled && cb.Morale > morale {
+			morale = cb.Morale
+		}
+	}
+	return morale
+}
+
+// checkMoraleTriggers runs the automatic B/X morale checks after a combatant falls:
+// once when the side suffers its first death, and once when half of it has been defeated.
+func checkMoraleTriggers(enc *Encounter, side CombatSide) {
+	total, defeated := 0, 0
+	for _, cb := range enc.Combatants {
+		if cb.Side != side {
+			continue
+		}
+		total++
+		if cb.Defeated {
+			defeated++
+		}
+	}
+
+	trigger := ""
+	switch {
+	case defeated == 1 && !hasMoraleCheck(enc, side, "first death"):
+		trigger = "first death"
+	case defeated*2 >= total && !hasMoraleCheck(enc, side, "half losses"):
+		trigger = "half losses"
+	default:
+		return
+	}
+	if sideMorale(enc, side) == 0 {
+		return
+	}
+	_, _ = CheckMorale(enc, side, trigger)
+}
+
+func hasMoraleCheck(enc *Encounter, side CombatSide, trigger string) bool {
+	for _, mc := range enc.MoraleChecks {
+		if mc.Side == side && mc.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// REPLAY
+
+// ReplayReport describes a replay of one party's journal.
+type ReplayReport struct {
+	CampaignID int
+	PartyID    int
+	UntilSeq   int   // the last event replayed
+	Events     int   // events read, including snapshots
+	Patches    int   // changes applied
+	Snapshots  int   // snapshots read
+	Drift      []int // snapshots that didn't match the replay up to them, by Seq
+}
+
+// ReplayParty rebuilds a party and its registries from the journal, up to and including event
+// untilSeq (0 for all of it). From the start, every change is applied on top of the party's first
+// snapshot; later snapshots are checked against the replay so far and then taken as they are, since
+// they also capture changes made outside the journal, such as loading a save. Otherwise the replay
+// starts from the last snapshot at or before untilSeq.
+func ReplayParty(campaignID, partyID, untilSeq int, fromStart bool) (partySnapshot, ReplayReport, error) {
+	report := ReplayReport{CampaignID: campaignID, PartyID: partyID, Drift: []int{}}
+	// Nothing before the snapshot the replay starts from needs reading
+	from := snapshotSeq(campaignID, partyID, untilSeq, fromStart)
+	events, err := ReadJournal(EventFilter{CampaignID: campaignID, PartyID: partyID, AfterSeq: max(from-1, 0)})
+	if err != nil {
+		return partySnapshot{}, report, err
+	}
+	start := -1
+	for i, e := range events {
+		if untilSeq > 0 && e.Seq > untilSeq {
+			events = events[:i]
+			break
+		}
+		if e.Type == EventSnapshot && (start < 0 || !fromStart) {
+			start = i
+		}
+	}
+	if start < 0 {
+		return partySnapshot{}, report, fmt.Errorf("the journal has no snapshot of party %d in campaign %d", partyID, campaignID)
+	}
+
+	var doc any
+	for _, e := range events[start:] {
+		report.Events++
+		report.UntilSeq = e.Seq
+		if e.Type == EventSnapshot {
+			report.Snapshots++
+			if doc != nil {
+				same, err := sameSnapshot(doc, e.Snapshot)
+				if err != nil {
+					return partySnapshot{}, report, fmt.Errorf("event %d: %w", e.Seq, err)
+				}
+				if !same {
+					report.Drift = append(report.Drift, e.Seq)
+				}
+			}
+			if doc, err = decodeJSON(e.Snapshot); err != nil {
+				return partySnapshot{}, report, fmt.Errorf("event %d: %w", e.Seq, err)
+			}
+		}
+		if isJSONValue(e.Patch) {
+			patch, err := decodeJSON(e.Patch)
+			if err != nil {
+				return partySnapshot{}, report, fmt.Errorf("event %d: %w", e.Seq, err)
+			}
+			doc = applyMergePatch(doc, patch)
+			report.Patches++
+		}
+	}
+
+	snap, err := snapshotFromJSON(doc)
+	if err != nil {
+		return partySnapshot{}, report, err
+	}
+	return snap, report, nil
+}
+
+// BranchParty adds a copy of a party, as replayed from the journal, to a campaign, to try out what
+// would have happened from that point.
+func BranchParty(c *Campaign, name string, snap partySnapshot) *PartyRecord {
+	pr := AddParty(c, name)
+	pr.Party = snap.Party
+	pr.Registry = snap.Registry
+	// The source's history is of changes made since, so the branch starts with nothing to undo
+	pr.History = History{}
+	return pr
+}
+
+// VerifyJournal replays every saved party from the start of the journal up to the point it was
+// saved and checks the result matches the save. Parties the journal has nothing on are skipped.
+// It returns a report per party checked and the ones that don't match.
+func VerifyJournal(save SaveFile) ([]ReplayReport, []ReplayReport, error) {
+	var reports, mismatched []ReplayReport
+	for _, c := range save.Campaigns {
+		for _, pr := range c.Parties {
+			events, err := ReadJournal(EventFilter{CampaignID: c.ID, PartyID: pr.ID, Type: EventSnapshot})
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(events) == 0 || (save.JournalSeq > 0 && events[0].Seq > save.JournalSeq) {
+				continue
+			}
+			snap, report, err := ReplayParty(c.ID, pr.ID, save.JournalSeq, true)
+			if err != nil {
+				return nil, nil, err
+			}
+			reports = append(reports, report)
+			saved, err := json.Marshal(partySnapshot{pr.Party, pr.Registry})
+			if err != nil {
+				return nil, nil, err
+			}
+			replayed, err := json.Marshal(snap)
+			if err != nil {
+				return nil, nil, err
+			}
+			doc, err := decodeJSON(replayed)
+			if err != nil {
+				return nil, nil, err
+			}
+			if same, err := sameSnapshot(doc, saved); err != nil {
+				return nil, nil, err
+			} else if !same {
+				mismatched = append(mismatched, report)
+			}
+		}
+	}
+	return reports, mismatched, nil
+}
+
+// JSON MERGE PATCHES
+
+// diffSnapshots returns the JSON merge patch (RFC 7396) that turns before into after, or nil if
+// they are the same.
+func diffSnapshots(before, after json.RawMessage) (json.RawMessage, error) {
+	if bytes.Equal(before, after) {
+		return nil, nil
+	}
+	b, err := decodeJSON(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := decodeJSON(after)
+	if err != nil {
+		return nil, err
+	}
+	patch, changed := mergeDiff(b, a)
+	if !changed {
+		return nil, nil
+	}
+	return json.Marshal(patch)
+}
+
+// mergeDiff compares two decoded JSON values. Objects are compared key by key, with removed keys
+// patched to null; anything else that differs is replaced whole.
+func mergeDiff(before, after any) (any, bool) {
+	bm, bok := before.(map[string]any)
+	am, aok := after.(map[string]any)
+	if !bok || !aok {
+		return after, !reflect.DeepEqual(before, after)
+	}
+	patch := map[string]any{}
+	for k := range bm {
+		if _, ok := am[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, av := range am {
+		bv, ok := bm[k]
+		if !ok {
+			patch[k] = av
+			continue
+		}
+		if sub, changed := mergeDiff(bv, av); changed {
+			patch[k] = sub
+		}
+	}
+	return patch, len(patch) > 0
+}
+
+// applyMergePatch applies a decoded JSON merge patch to a decoded JSON value.
+func applyMergePatch(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = applyMergePatch(tm[k], v)
+	}
+	return tm
+}
+
+// isJSONValue reports whether a raw field holds something other than null, as unset fields read back
+// from the journal do.
+func isJSONValue(raw json.RawMessage) bool {
+	return len(raw) > 0 && !bytes.Equal(raw, []byte("null"))
+}
+
+// decodeJSON decodes into generic values, keeping numbers exact.
+func decodeJSON(raw json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// snapshotFromJSON turns a replayed document back into a party and registries.
+func snapshotFromJSON(doc any) (partySnapshot, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return partySnapshot{}, err
+	}
+	var snap partySnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return partySnapshot{}, fmt.Errorf("replayed party is not valid: %w", err)
+	}
+	fillPartyRegistry(&snap.Registry)
+	return snap, nil
+}
+
+// sameSnapshot reports whether a replayed document and a snapshot hold the same party and
+// registries, ignoring differences that don't survive a round trip, like nil and empty maps.
+func sameSnapshot(doc any, raw json.RawMessage) (bool, error) {
+	replayed, err := snapshotFromJSON(doc)
+	if err != nil {
+		return false, err
+	}
+	other, err := decodeJSON(raw)
+	if err != nil {
+		return false, err
+	}
+	snap, err := snapshotFromJSON(other)
+	if err != nil {
+		return false, err
+	}
+	a, err := json.Marshal(replayed)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(a, b), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// verifySave checks the journal replays to what was last saved.
+func verifySave(t *testing.T) {
+	t.Helper()
+	data, err := os.ReadFile(SavePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var save SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		t.Fatal(err)
+	}
+	reports, mismatched, err := VerifyJournal(save)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatched) > 0 {
+		t.Errorf("journal does not replay to the save: %+v (of %+v)", mismatched, reports)
+	}
+}
+
+func TestVerifyAfterCharacterMovesIn(t *testing.T) {
+	resetState(t)
+	SavePath = filepath.Join(t.TempDir(), "save.json")
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	// The second party has a journal of its own before Aldric joins it
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties", `{"Name":"Second"}`)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties/2/characters", `{"Name":"Nim"}`)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties/1/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/campaigns/1/parties/1/characters/1/move", `{"PartyID":2}`)
+	apiRequest(t, e, "POST", "/api/save", "")
+	verifySave(t)
+}
+
+func TestVerifyAfterLoad(t *testing.T) {
+	resetState(t)
+	SavePath = filepath.Join(t.TempDir(), "save.json")
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Aldric"}`)
+	apiRequest(t, e, "POST", "/api/save", "")
+	apiRequest(t, e, "POST", "/api/characters", `{"Name":"Mirel"}`)
+	apiRequest(t, e, "POST", "/api/load", "")
+	apiRequest(t, e, "POST", "/api/save", "")
+	verifySave(t)
+}
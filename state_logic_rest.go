@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+)
+
+// REST
+
+type RestKind string
+
+const (
+	RestNight   RestKind = "night"   // a night's sleep between adventuring days
+	RestBedRest RestKind = "bedrest" // a full day spent in bed, plus the night that follows
+	RestCustom  RestKind = "custom"  // any number of days of complete rest
+)
+
+// RestConfig holds the healing rules used when the party rests.
+type RestConfig struct {
+	HealDie     int // B/X: 1d3 hit points per day of rest
+	BedRestDice int // dice rolled for a full day of bed rest (1 by the book; more is a house rule)
+}
+
+var RestRules = RestConfig{3, 1}
+
+// RestResult reports what a rest did for a single character.
+type RestResult struct {
+	CharacterID      int
+	Name             string
+	Days             int
+	HitPointsHealed  int
+	CurrentHitPoints int
+	SpellsReset      int
+	Status           CharacterStatus
+	Rations          []ClockEvent // a ration pack finished, or a day gone hungry
+}
+
+// RestParty rests every living character in the party and advances the campaign clock to the start
+// of the next day. The party eats its rations for each day that passes.
+// Dead and petrified characters don't rest and are left out of the results.
+// Each character resets all memorized spells, heals (never past MaximumHitPoints),
+// and may then prepare a new list of spells.
+// days is only used by RestCustom; a night or a day of bed rest always counts as one day.
+func RestParty(p *Party, kind RestKind, days int) ([]RestResult, error) {
+	dice, err := restHealingDice(kind, days)
+	if err != nil {
+		return nil, err
+	}
+	if kind != RestCustom {
+		days = 1
+	}
+
+	rations := map[int][]ClockEvent{}
+	for range days {
+		fed := startNewDay(p)
+		for _, ch := range p.Characters {
+			for _, msg := range fed[ch.ID] {
+				rations[ch.ID] = append(rations[ch.ID], ClockEvent{p.Day, 0, ClockRations, msg})
+				emit(EventClock, []int{ch.ID}, nil, "%s", msg)
+			}
+		}
+	}
+	p.Turn = 0
+	p.TurnsSinceRest = 0
+	emit(EventRest, nil, nil, "The party rested (%s) until day %d", kind, p.Day)
+	if _, err := AdvanceEffects(p, days, DurationDays); err != nil {
+		return nil, err
+	}
+	results := make([]RestResult, 0, len(p.Characters))
+	for i := range p.Characters {
+		if ch := &p.Characters[i]; IsDead(ch) || ch.Status == StatusPetrified {
+			continue
+		}
+		result := restCharacter(&p.Characters[i], days, dice)
+		result.Rations = rations[result.CharacterID]
+		results = append(results, result)
+		OpenSpellPreparation(&p.Characters[i], p.Day)
+	}
+	return results, nil
+}
+
+func RestPartyNight(p *Party) ([]RestResult, error) { return RestParty(p, RestNight, 1) }
+func RestPartyBedRest(p *Party) ([]RestResult, error) {
+	return RestParty(p, RestBedRest, 1)
+}
+
+// restHealingDice returns how many healing dice each character rolls for a rest.
+func restHealingDice(kind RestKind, days int) (int, error) {
+	switch kind {
+	case RestNight:
+		return 1, nil
+	case RestBedRest:
+		return RestRules.BedRestDice, nil
+	case RestCustom:
+		if days < 1 {
+			return 0, fmt.Errorf("rest must last at least 1 day")
+		}
+		return days, nil
+	default:
+		return 0, fmt.Errorf("invalid rest kind: %q", kind)
+	}
+}
+
+func restCharacter(c *Character, days, dice int) RestResult {
+	result := RestResult{
+		CharacterID: c.ID,
+		Name:        c.Name,
+		Days:        days,
+	}
+
+	for _, ms := range c.MemorizedSpells {
+		if ms.Cast {
+			result.SpellsReset++
+		}
+	}
+	ResetAllMemorizedSpells(c)
+
+	if c.CurrentHitPoints < c.MaximumHitPoints {
+		healed := RollDice(dice, RestRules.HealDie)
+		if c.CurrentHitPoints+healed > c.MaximumHitPoints {
+			healed = c.MaximumHitPoints - c.CurrentHitPoints
+		}
+		c.CurrentHitPoints += healed
+		result.HitPointsHealed = healed
+		UpdateCharacterStatus(c)
+	}
+	result.CurrentHitPoints = c.CurrentHitPoints
+	result.Status = c.Status
+	return result
+}
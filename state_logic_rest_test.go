@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestRestReportsHungerInTheResults(t *testing.T) {
+	resetState(t)
+	ch := AddCharacter(&State, "Aldric")
+
+	results, err := RestParty(&State, RestCustom, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].CharacterID != ch.ID {
+		t.Fatalf("got results %+v, want one for Aldric", results)
+	}
+	rations := results[0].Rations
+	if want := 2 * ClockRules.RationsPerDay; len(rations) != want {
+		t.Fatalf("got %d ration events, want %d: %+v", len(rations), want, rations)
+	}
+	if r := rations[len(rations)-1]; r.Kind != ClockRations || r.Day != State.Day {
+		t.Errorf("last ration event is %+v, want rations on day %d", r, State.Day)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RETAINERS
+
+const DaysPerMonth = 28
+
+// RetainerConfig holds the default terms offered to new retainers.
+type RetainerConfig struct {
+	XPShare int // percent of a full share of experience. B/X: 50
+}
+
+var RetainerRules = RetainerConfig{50}
+
+// Retainers returns the living retainers employed by a character.
+func Retainers(employerID int, p *Party) []*Character {
+	var out []*Character
+	for i := range p.Characters {
+		ch := &p.Characters[i]
+		if ch.Retainer != nil && ch.Retainer.EmployerID == employerID && !IsDead(ch) {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// HireRetainer adds a new NPC to the party in the employ of a player character. The number of
+// retainers a character can keep and their loyalty both come from the employer's Charisma.
+// The first month's wage is paid up front out of the employer's Gold.
+func HireRetainer(employerID int, name string, wage int, p *Party) (*Character, error) {
+	employer, err := FindChar(p, employerID)
+	if err != nil {
+		return nil, err
+	}
+	if employer.Retainer != nil {
+		return nil, fmt.Errorf("%s is a retainer and cannot hire retainers", employer.Name)
+	}
+	if err := checkCharacterCanAct(employer); err != nil {
+		return nil, err
+	}
+	if wage < 0 {
+		return nil, fmt.Errorf("wage cannot be negative")
+	}
+	if limit := MaxRetainers(employer.Charisma); len(Retainers(employerID, p)) >= limit {
+		return nil, fmt.Errorf("%s cannot employ more than %d retainers", employer.Name, limit)
+	}
+	if employer.Gold < wage {
+		return nil, fmt.Errorf("%s cannot afford %d gold for the first month", employer.Name, wage)
+	}
+	employer.Gold -= wage
+	loyalty := RetainerMorale(employer.Charisma)
+
+	// AddCharacter appends to the party, so employer is not valid past this point
+	char := AddCharacter(p, name)
+	ch, _ := FindChar(p, char.ID)
+	ch.Retainer = &Retainer{
+		EmployerID:     employerID,
+		Wage:           wage,
+		Loyalty:        loyalty,
+		XPShare:        RetainerRules.XPShare,
+		HiredDay:       p.Day,
+		PaidThroughDay: p.Day + DaysPerMonth,
+		LoyaltyRecord:  []LoyaltyEntry{},
+	}
+	emit(EventRetainer, []int{employerID, ch.ID}, nil, "%s was hired for %d gold a month", ch.Name, wage)
+	return ch, nil
+}
+
+// DismissRetainer lets a retainer go. Like any character leaving the party, what they were
+// carrying is left in limbo. If they can't leave, they stay on as a retainer.
+func DismissRetainer(retainerID int, p *Party) error {
+	return Transact(p, func() error {
+		ch, err := findRetainer(retainerID, p)
+		if err != nil {
+			return err
+		}
+		employerID := ch.Retainer.EmployerID
+		ch.Retainer = nil
+		emit(EventRetainer, []int{employerID, retainerID}, nil, "%s was dismissed", ch.Name)
+		return DeleteCharacter(retainerID, p)
+	})
+}
+
+// PayWages pays every retainer of a character whose wages have come due, a month at a time, out
+// of the employer's Gold. Retainers who can't be paid are left owed and listed in the error.
+func PayWages(employerID int, p *Party) (int, error) {
+	employer, err := FindChar(p, employerID)
+	if err != nil {
+		return 0, err
+	}
+	paid := 0
+	var unpaid []string
+	for _, r := range Retainers(employerID, p) {
+		for r.Retainer.PaidThroughDay <= p.Day {
+			if employer.Gold < r.Retainer.Wage {
+				unpaid = append(unpaid, r.Name)
+				break
+			}
+			employer.Gold -= r.Retainer.Wage
+			paid += r.Retainer.Wage
+			r.Retainer.PaidThroughDay += DaysPerMonth
+			emit(EventRetainer, []int{employerID, r.ID}, nil, "%s paid %s %d gold", employer.Name, r.Name, r.Retainer.Wage)
+		}
+	}
+	if len(unpaid) > 0 {
+		return paid, fmt.Errorf("%s cannot afford to pay %s", employer.Name, strings.Join(unpaid, ", "))
+	}
+	return paid, nil
+}
+
+// CheckLoyalty rolls 2d6 against a retainer's loyalty, as when they are asked to face great danger
+// or go unpaid. A roll over their loyalty fails.
+func CheckLoyalty(retainerID int, reason string, p *Party) (LoyaltyEntry, error) {
+	ch, err := findRetainer(retainerID, p)
+	if err != nil {
+		return LoyaltyEntry{}, err
+	}
+	r := ch.Retainer
+	entry := LoyaltyEntry{
+		Day:     p.Day,
+		Reason:  reason,
+		Roll:    RollDice(2, 6),
+		Loyalty: r.Loyalty,
+	}
+	entry.Passed = r.Loyalty >= 12 || entry.Roll <= r.Loyalty
+	r.LoyaltyRecord = append(r.LoyaltyRecord, entry)
+	outcome := "held"
+	if !entry.Passed {
+		outcome = "failed"
+	}
+	emit(EventRetainer, []int{retainerID}, nil, "%s's loyalty %s (rolled %d against %d)", ch.Name, outcome, entry.Roll, r.Loyalty)
+	return entry, nil
+}
+
+// AdjustLoyalty raises or lowers a retainer's loyalty, for fair treatment, a share of treasure,
+// or abuse. Loyalty stays between 2 and 12.
+func AdjustLoyalty(retainerID, change int, reason string, p *Party) (LoyaltyEntry, error) {
+	ch, err := findRetainer(retainerID, p)
+	if err != nil {
+		return LoyaltyEntry{}, err
+	}
+	if change == 0 {
+		return LoyaltyEntry{}, fmt.Errorf("loyalty change cannot be 0")
+	}
+	r := ch.Retainer
+	r.Loyalty = min(max(r.Loyalty+change, 2), 12)
+	entry := LoyaltyEntry{
+		Day:     p.Day,
+		Reason:  reason,
+		Change:  change,
+		Loyalty: r.Loyalty,
+	}
+	r.LoyaltyRecord = append(r.LoyaltyRecord, entry)
+	emit(EventRetainer, []int{retainerID}, nil, "%s's loyalty is now %d", ch.Name, r.Loyalty)
+	return entry, nil
+}
+
+// SetRetainerTerms renegotiates a retainer's monthly wage and share of experience.
+func SetRetainerTerms(retainerID, wage, xpShare int, p *Party) error {
+	ch, err := findRetainer(retainerID, p)
+	if err != nil {
+		return err
+	}
+	if wage < 0 {
+		return fmt.Errorf("wage cannot be negative")
+	}
+	if xpShare < 0 || xpShare > 100 {
+		return fmt.Errorf("experience share must be between 0 and 100 percent")
+	}
+	ch.Retainer.Wage = wage
+	ch.Retainer.XPShare = xpShare
+	emit(EventRetainer, []int{retainerID}, nil, "%s now works for %d gold a month and %d%% of a share", ch.Name, wage, xpShare)
+	return nil
+}
+
+func findRetainer(id int, p *Party) (*Character, error) {
+	ch, err := FindChar(p, id)
+	if err != nil {
+		return nil, err
+	}
+	if ch.Retainer == nil {
+		return nil, fmt.Errorf("%s is not a retainer", ch.Name)
+	}
+	return ch, nil
+}
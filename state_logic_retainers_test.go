@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRetainerTermsKeepXPShareWhenOmitted(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+
+	ch := AddCharacter(&State, "Aldric")
+	c, _ := FindChar(&State, ch.ID)
+	c.Charisma = 13
+	c.Gold = 100
+	apiRequest(t, e, "POST", "/api/characters/1/retainers", `{"Name":"Nim","Wage":10}`)
+	apiRequest(t, e, "PUT", "/api/retainers/2", `{"Wage":15,"XPShare":30}`)
+	apiRequest(t, e, "PUT", "/api/retainers/2", `{"Wage":20}`)
+
+	r, err := findRetainer(2, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Retainer.Wage != 20 || r.Retainer.XPShare != 30 {
+		t.Errorf("terms are %d gold and %d%%, want 20 gold and 30%%", r.Retainer.Wage, r.Retainer.XPShare)
+	}
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SPREADSHEET IMPORT
+
+// RosterField is a character detail a spreadsheet column can hold.
+type RosterField string
+
+const (
+	RosterIgnore           RosterField = "ignore"
+	RosterName             RosterField = "name"
+	RosterClass            RosterField = "class"
+	RosterLevel            RosterField = "level"
+	RosterAlignment        RosterField = "alignment"
+	RosterStrength         RosterField = "strength"
+	RosterIntelligence     RosterField = "intelligence"
+	RosterWisdom           RosterField = "wisdom"
+	RosterDexterity        RosterField = "dexterity"
+	RosterConstitution     RosterField = "constitution"
+	RosterCharisma         RosterField = "charisma"
+	RosterHitPoints        RosterField = "hp" // maximum hit points, or "current/maximum"
+	RosterCurrentHitPoints RosterField = "currenthp"
+	RosterExperience       RosterField = "xp"
+	RosterGold             RosterField = "gold"
+	RosterItems            RosterField = "items" // separated by semicolons, bars or commas
+)
+
+// rosterAliases are the headers each field is recognised by, normalized with rosterKey.
+var rosterAliases = map[RosterField][]string{
+	RosterName:             {"name", "character", "charactername", "pc"},
+	RosterClass:            {"class"},
+	RosterLevel:            {"level", "lvl", "lv"},
+	RosterAlignment:        {"alignment", "align", "al"},
+	RosterStrength:         {"strength", "str"},
+	RosterIntelligence:     {"intelligence", "int"},
+	RosterWisdom:           {"wisdom", "wis"},
+	RosterDexterity:        {"dexterity", "dex"},
+	RosterConstitution:     {"constitution", "con"},
+	RosterCharisma:         {"charisma", "cha"},
+	RosterHitPoints:        {"hp", "hitpoints", "maxhp", "hpmax", "maximumhitpoints"},
+	RosterCurrentHitPoints: {"currenthp", "hpcurrent", "curhp", "currenthitpoints"},
+	RosterExperience:       {"xp", "experience", "exp"},
+	RosterGold:             {"gold", "gp"},
+	RosterItems:            {"items", "equipment", "gear", "inventory"},
+}
+
+// RosterOptions say how to read a spreadsheet.
+type RosterOptions struct {
+	Delimiter string                 // ",", ";" or "tab"; worked out from the header row when empty
+	Columns   map[string]RosterField // header to field, for headers the importer doesn't recognise
+}
+
+// RosterColumn is how one header was read. Field is empty for a column that was ignored.
+type RosterColumn struct {
+	Header string
+	Field  RosterField
+}
+
+// RosterRow is what became of one row of the spreadsheet.
+type RosterRow struct {
+	Line        int
+	Name        string
+	Accepted    bool
+	CharacterID int      // the ID the character was given, or would be given by a preview
+	Reasons     []string // why the row was rejected
+	Notes       []string // what was done differently than the row asked, e.g. armor carried but not worn
+}
+
+// RosterReport is the outcome of importing a spreadsheet. Until Committed, nothing has been added.
+type RosterReport struct {
+	Columns   []RosterColumn
+	Rows      []RosterRow
+	Accepted  int
+	Rejected  int
+	Committed bool
+}
+
+var errRosterPreview = errors.New("roster preview")
+
+// ImportRoster adds a character for each row of a CSV or TSV spreadsheet. Every row is checked
+// like a character sheet edit and set up through the usual inventory rules; rows that fail are
+// rejected with their reasons and the rest are accepted. Unless commit is set it is only a
+// preview: the report is the same, but the party is left untouched.
+func ImportRoster(data string, opts RosterOptions, commit bool, p *Party) (RosterReport, error) {
+	records, lines, err := readRoster(data, opts.Delimiter)
+	if err != nil {
+		return RosterReport{}, err
+	}
+	if len(records) == 0 {
+		return RosterReport{}, fmt.Errorf("spreadsheet is empty")
+	}
+	columns, err := rosterColumns(records[0], opts.Columns)
+	if err != nil {
+		return RosterReport{}, err
+	}
+
+	report := RosterReport{Columns: columns, Rows: []RosterRow{}}
+	err = Transact(p, func() error {
+		for i, record := range records[1:] {
+			row := importRosterRow(record, columns, p)
+			row.Line = lines[i+1]
+			if row.Accepted {
+				report.Accepted++
+			} else {
+				report.Rejected++
+			}
+			report.Rows = append(report.Rows, row)
+		}
+		if !commit {
+			return errRosterPreview
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRosterPreview) {
+		return RosterReport{}, err
+	}
+	report.Committed = commit
+	return report, nil
+}
+
+// readRoster splits a spreadsheet into records, with the line each one starts on.
+func readRoster(data, delimiter string) ([][]string, []int, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+	switch delimiter {
+	case "":
+		r.Comma = guessDelimiter(data)
+	case "tab", `\t`, "\t":
+		r.Comma = '\t'
+	case ",", ";":
+		r.Comma = rune(delimiter[0])
+	default:
+		return nil, nil, fmt.Errorf("invalid delimiter: %q", delimiter)
+	}
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
+// guessDelimiter picks whichever of comma, tab and semicolon splits the header row into the most
+// columns. The header is read as CSV for each, so a delimiter inside a quoted header doesn't count.
+func guessDelimiter(data string) rune {
+	best, most := ',', 0
+	for _, d := range []rune{',', '\t', ';'} {
+		r := csv.NewReader(strings.NewReader(data))
+		r.Comma = d
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+		if header, err := r.Read(); err == nil && len(header) > most {
+			best, most = d, len(header)
+		}
+	}
+	return best
+}
+
+// rosterColumns works out the field in each column from its header. Headers named in columns
+// take that field; the rest are matched against rosterAliases, and ignored if nothing matches.
+func rosterColumns(headers []string, columns map[string]RosterField) ([]RosterColumn, error) {
+	mapped := map[string]RosterField{}
+	for header, field := range columns {
+		if _, ok := rosterAliases[field]; !ok && field != RosterIgnore {
+			return nil, fmt.Errorf("invalid field for column %q: %q", header, field)
+		}
+		mapped[rosterKey(header)] = field
+	}
+
+	out := make([]RosterColumn, len(headers))
+	seen := map[RosterField]string{}
+	for i, header := range headers {
+		key := rosterKey(header)
+		field, ok := mapped[key]
+		if !ok {
+			field = rosterAlias(key)
+		}
+		if field == RosterIgnore {
+			field = ""
+		}
+		if field != "" {
+			if other, dup := seen[field]; dup {
+				return nil, fmt.Errorf("columns %q and %q both hold %s", other, header, field)
+			}
+			seen[field] = header
+		}
+		out[i] = RosterColumn{Header: header, Field: field}
+	}
+	if _, ok := seen[RosterName]; !ok {
+		return nil, fmt.Errorf("no column holds the character's name")
+	}
+	return out, nil
+}
+
+func rosterAlias(key string) RosterField {
+	for field, aliases := range rosterAliases {
+		for _, alias := range aliases {
+			if key == alias {
+				return field
+			}
+		}
+	}
+	return ""
+}
+
+// rosterKey normalizes a header or name for matching: lower case, letters and digits only.
+func rosterKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, s)
+}
+
+// importRosterRow adds the character in one row, or leaves the party as it was and says why not.
+func importRosterRow(record []string, columns []RosterColumn, p *Party) RosterRow {
+	cells := map[RosterField]string{}
+	for i, col := range columns {
+		if col.Field != "" && i < len(record) {
+			cells[col.Field] = strings.TrimSpace(record[i])
+		}
+	}
+	row := RosterRow{Name: cells[RosterName], Reasons: []string{}, Notes: []string{}}
+
+	patch, reasons := rosterPatch(cells)
+	if row.Name == "" {
+		reasons = append([]string{"missing name"}, reasons...)
+	}
+	if err := ValidateCharacterPatch(patch); err != nil {
+		reasons = append(reasons, err.Error())
+	}
+	if len(reasons) > 0 {
+		row.Reasons = reasons
+		return row
+	}
+
+	err := Transact(p, func() error {
+		ch := AddCharacter(p, row.Name)
+		row.CharacterID = ch.ID
+		c, err := FindChar(p, ch.ID)
+		if err != nil {
+			return err
+		}
+		ApplyCharacterPatch(c, patch)
+		c.Spellcasting = classSpellcasting(c.Class)
+		notes, err := giveRosterItems(c.ID, cells[RosterItems], p)
+		row.Notes = notes
+		return err
+	})
+	if err != nil {
+		row.CharacterID = 0
+		row.Notes = []string{}
+		row.Reasons = []string{err.Error()}
+		return row
+	}
+	row.Accepted = true
+	return row
+}
+
+// rosterPatch turns a row's cells into a character sheet edit, with a reason for each cell it
+// can't read.
+func rosterPatch(cells map[RosterField]string) (CharacterPatch, []string) {
+	var patch CharacterPatch
+	var reasons []string
+	number := func(field RosterField, dst **int) {
+		cell := cells[field]
+		if cell == "" {
+			return
+		}
+		n, err := strconv.Atoi(cell)
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %q is not a number", field, cell))
+			return
+		}
+		*dst = &n
+	}
+
+	if cell := cells[RosterClass]; cell != "" {
+		class := rosterClass(cell)
+		patch.Class = &class
+	}
+	number(RosterLevel, &patch.Level)
+	if cell := cells[RosterAlignment]; cell != "" {
+		alignment := rosterAlignment(cell)
+		patch.Alignment = &alignment
+	}
+	number(RosterStrength, &patch.Strength)
+	number(RosterIntelligence, &patch.Intelligence)
+	number(RosterWisdom, &patch.Wisdom)
+	number(RosterDexterity, &patch.Dexterity)
+	number(RosterConstitution, &patch.Constitution)
+	number(RosterCharisma, &patch.Charisma)
+	number(RosterExperience, &patch.Experience)
+	number(RosterGold, &patch.Gold)
+
+	// Hit points may be written "current/maximum" in a single column
+	hp := cells[RosterHitPoints]
+	if current, maximum, ok := strings.Cut(hp, "/"); ok {
+		hp = strings.TrimSpace(maximum)
+		if cells[RosterCurrentHitPoints] == "" {
+			cells[RosterCurrentHitPoints] = strings.TrimSpace(current)
+		}
+	}
+	cells[RosterHitPoints] = hp
+	number(RosterHitPoints, &patch.MaximumHitPoints)
+	number(RosterCurrentHitPoints, &patch.CurrentHitPoints)
+	if patch.MaximumHitPoints != nil {
+		patch.RolledHitPoints = patch.MaximumHitPoints
+		if patch.CurrentHitPoints == nil {
+			patch.CurrentHitPoints = patch.MaximumHitPoints
+		}
+	} else if patch.CurrentHitPoints != nil {
+		reasons = append(reasons, "current hit points given without maximum hit points")
+	}
+	return patch, reasons
+}
+
+func rosterClass(cell string) CharacterClass {
+	switch key := rosterKey(cell); key {
+	case "mu", "magicuser", "mage":
+		return ClassMagicUser
+	default:
+		return CharacterClass(key)
+	}
+}
+
+func rosterAlignment(cell string) Alignment {
+	switch key := rosterKey(cell); key {
+	case "l":
+		return AlignmentLawful
+	case "n":
+		return AlignmentNeutral
+	case "c":
+		return AlignmentChaotic
+	default:
+		return Alignment(key)
+	}
+}
+
+// classSpellcasting is the kind of spells a class casts.
+func classSpellcasting(class CharacterClass) []SpellType {
+	switch class {
+	case ClassCleric:
+		return []SpellType{SpellDivine}
+	case ClassMagicUser, ClassElf:
+		return []SpellType{SpellArcane}
+	}
+	return []SpellType{}
+}
+
+// Items
+
+// rosterWeapon is a weapon from the B/X equipment list.
+type rosterWeapon struct {
+	damage                          int
+	melee, ranged, twoHanded, blunt bool
+}
+
+// rosterWeapons are the B/X weapons, by name normalized with rosterKey.
+var rosterWeapons = map[string]rosterWeapon{
+	"battleaxe":      {8, true, false, true, false},
+	"handaxe":        {6, true, true, false, false},
+	"crossbow":       {6, false, true, true, false},
+	"longbow":        {6, false, true, true, false},
+	"shortbow":       {6, false, true, true, false},
+	"dagger":         {4, true, true, false, false},
+	"silverdagger":   {4, true, true, false, false},
+	"shortsword":     {6, true, false, false, false},
+	"sword":          {8, true, false, false, false},
+	"longsword":      {8, true, false, false, false},
+	"twohandedsword": {10, true, false, true, false},
+	"mace":           {6, true, false, false, true},
+	"club":           {4, true, false, false, true},
+	"polearm":        {10, true, false, true, false},
+	"sling":          {4, false, true, false, true},
+	"spear":          {6, true, true, false, false},
+	"staff":          {4, true, false, true, true},
+	"warhammer":      {6, true, false, false, true},
+}
+
+// rosterArmor are the armors, by name normalized with rosterKey.
+var rosterArmor = map[string]ArmorType{
+	"robes": Robes, "robe": Robes,
+	"leather": Leather, "leatherarmor": Leather,
+	"chain": Chain, "chainmail": Chain, "chainarmor": Chain,
+	"plate": Plate, "platemail": Plate, "platearmor": Plate,
+}
+
+// magicBonus matches a "+1" to "+3" at the end of an item's name.
+var magicBonus = regexp.MustCompile(`\s*\+([1-3])$`)
+
+// giveRosterItems creates the items in a row's item list and hands them to the character. Weapons,
+// armor and shields on the B/X equipment list are created as such; anything else is a plain item.
+// The first armor and shield are worn if the character's class allows it.
+func giveRosterItems(charID int, cell string, p *Party) ([]string, error) {
+	notes := []string{}
+	wornArmor, wornShield := false, false
+	for _, name := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == '|' || r == ',' }) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bonus := 0
+		key := name
+		if m := magicBonus.FindStringSubmatch(name); m != nil {
+			bonus, _ = strconv.Atoi(m[1])
+			key = name[:len(name)-len(m[0])]
+		}
+		key = rosterKey(key)
+
+		var id int
+		var equip func(charID, itemID int, p *Party) error
+		if w, ok := rosterWeapons[key]; ok {
+			id = NewWeapon(name, w.damage, bonus, w.melee, w.ranged, w.twoHanded, w.blunt, LocationStorage).ID
+		} else if armorType, ok := rosterArmor[key]; ok {
+			id = NewArmor(name, armorType, bonus, LocationStorage).ID
+			if !wornArmor {
+				equip, wornArmor = EquipArmor, true
+			}
+		} else if key == "shield" {
+			id = NewShield(name, bonus, LocationStorage).ID
+			if !wornShield {
+				equip, wornShield = EquipShield, true
+			}
+		} else {
+			id = NewGenericItem(name, LocationStorage).ID
+		}
+
+		if err := moveItemToCharacter(id, charID, p); err != nil {
+			return notes, fmt.Errorf("%s: %w", name, err)
+		}
+		if equip != nil {
+			if err := equip(charID, id, p); err != nil {
+				notes = append(notes, fmt.Sprintf("%s is carried, not worn: %v", name, err))
+			}
+		}
+	}
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return notes, err
+	}
+	return notes, ValidateCharacterInventory(ch)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRosterPreviewLeavesThePartyAlone(t *testing.T) {
+	resetState(t)
+	data := "Name,Class,Level,Items\nAldric,Fighter,2,Sword;Chain Mail\nMirel,Magic-User,1,Dagger\n"
+	before := snapshotState(t)
+
+	preview, err := ImportRoster(data, RosterOptions{}, false, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if preview.Committed || preview.Accepted != 2 {
+		t.Fatalf("preview accepted %d rows (committed %v), want 2 uncommitted", preview.Accepted, preview.Committed)
+	}
+	assertUnchanged(t, before)
+
+	report, err := ImportRoster(data, RosterOptions{}, true, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Committed || report.Accepted != 2 || len(State.Characters) != 2 {
+		t.Fatalf("commit accepted %d rows and the party has %d characters, want 2 and 2", report.Accepted, len(State.Characters))
+	}
+	for i, row := range report.Rows {
+		if row.CharacterID != preview.Rows[i].CharacterID {
+			t.Errorf("%s was given ID %d, but the preview said %d", row.Name, row.CharacterID, preview.Rows[i].CharacterID)
+		}
+	}
+	c, _ := FindChar(&State, report.Rows[0].CharacterID)
+	if c.ArmorID == NoItemEquipped || len(c.Items) != 2 {
+		t.Errorf("Aldric carries %v and wears %d, want a sword and chain mail worn", c.Items, c.ArmorID)
+	}
+}
+
+func TestRosterGuessesTheDelimiter(t *testing.T) {
+	cases := map[string]rune{
+		"Name\tClass\tHP\nAldric\tFighter\t8\n":         '\t',
+		"Name;Class;HP\nAldric;Fighter;8\n":             ';',
+		"Name,Class,HP\nAldric,Fighter,8\n":             ',',
+		"\"Name, Title, Rank\";Class\nAldric;Fighter\n": ';',
+		"\"Name; Title; Rank\",Class\nAldric,Fighter\n": ',',
+	}
+	for data, want := range cases {
+		if got := guessDelimiter(data); got != want {
+			t.Errorf("guessDelimiter(%q) = %q, want %q", data, got, want)
+		}
+	}
+
+	resetState(t)
+	opts := RosterOptions{Columns: map[string]RosterField{"Name, Title": RosterName}}
+	report, err := ImportRoster("\"Name, Title\";Class;Level\nAldric the Bold;Fighter;2\n", opts, true, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Accepted != 1 || State.Characters[0].Class != ClassFighter {
+		t.Errorf("a quoted header with a comma was misread: %+v", report)
+	}
+}
+
+func TestRosterReadsCurrentAndMaximumHitPoints(t *testing.T) {
+	resetState(t)
+	data := "Name,HP\nAldric,4/7\nMirel,6\nNim,x/5\n"
+	report, err := ImportRoster(data, RosterOptions{}, true, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]int{"Aldric": {4, 7}, "Mirel": {6, 6}}
+	for _, c := range State.Characters {
+		if hp := want[c.Name]; c.CurrentHitPoints != hp[0] || c.MaximumHitPoints != hp[1] {
+			t.Errorf("%s has %d/%d hit points, want %d/%d", c.Name, c.CurrentHitPoints, c.MaximumHitPoints, hp[0], hp[1])
+		}
+	}
+	if report.Rejected != 1 || report.Rows[2].Accepted {
+		t.Errorf("unreadable current hit points should reject the row: %+v", report.Rows[2])
+	}
+}
+
+func TestRosterColumns(t *testing.T) {
+	resetState(t)
+	if _, err := ImportRoster("Name,Character\nAldric,Mirel\n", RosterOptions{}, false, &State); err == nil {
+		t.Error("two columns holding the name should be refused")
+	}
+	if _, err := ImportRoster("Player,Class\nAnn,Fighter\n", RosterOptions{}, false, &State); err == nil {
+		t.Error("a spreadsheet with no name column should be refused")
+	}
+
+	data := "Hero,Player,Lvl\nAldric,Ann,3\n"
+	opts := RosterOptions{Columns: map[string]RosterField{"Hero": RosterName}}
+	report, err := ImportRoster(data, opts, true, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RosterColumn{{"Hero", RosterName}, {"Player", ""}, {"Lvl", RosterLevel}}
+	for i, col := range report.Columns {
+		if col != want[i] {
+			t.Errorf("column %d read as %+v, want %+v", i, col, want[i])
+		}
+	}
+	if len(State.Characters) != 1 || State.Characters[0].Level != 3 {
+		t.Errorf("party is %+v, want Aldric at level 3", State.Characters)
+	}
+}
+
+func TestRejectedRosterRowsLeaveNothingBehind(t *testing.T) {
+	resetState(t)
+	tooMuch := strings.Repeat("Torch;", 11)
+	data := "Name,Items,Strength\nAldric,Sword,12\nMirel," + tooMuch + ",10\nNim,,strong\n"
+	report, err := ImportRoster(data, RosterOptions{}, true, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Accepted != 1 || report.Rejected != 2 {
+		t.Fatalf("accepted %d and rejected %d rows, want 1 and 2", report.Accepted, report.Rejected)
+	}
+	for _, row := range report.Rows[1:] {
+		if row.CharacterID != 0 || len(row.Reasons) == 0 {
+			t.Errorf("rejected row %+v should have reasons and no character", row)
+		}
+	}
+
+	// Only Aldric and his sword are left, as if the other rows had never been read
+	resetState(t)
+	if _, err := ImportRoster("Name,Items,Strength\nAldric,Sword,12\n", RosterOptions{}, true, &State); err != nil {
+		t.Fatal(err)
+	}
+	want := snapshotState(t)
+	resetState(t)
+	if _, err := ImportRoster(data, RosterOptions{}, true, &State); err != nil {
+		t.Fatal(err)
+	}
+	if got := snapshotState(t); !bytes.Equal(got, want) {
+		t.Errorf("rejected rows left something behind:\nwant %s\ngot  %s", want, got)
+	}
+}
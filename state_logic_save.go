@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// SAVING
+
+// SavePath is where campaigns are saved, relative to the working directory.
+var SavePath = "dungeon-party.json"
+
+// SaveFile is everything written to disk: every campaign with all of its parties and their history.
+// JournalSeq is the last event in the journal when it was saved.
+type SaveFile struct {
+	Campaigns      []*Campaign
+	NextCampaignID int
+	JournalSeq     int
+}
+
+// SaveCampaigns writes every campaign to path. The file is written alongside and then moved into
+// place, so a failed save never leaves a half-written file behind.
+func SaveCampaigns(path string) error {
+	stashActiveParty()
+	data, err := json.MarshalIndent(SaveFile{Campaigns, nextCampaignID, nextEventSeq - 1}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot save: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("cannot save: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("cannot save: %w", err)
+	}
+	return nil
+}
+
+// LoadCampaigns replaces every campaign with the ones saved at path and activates the default party.
+func LoadCampaigns(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot load: %w", err)
+	}
+	var save SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return fmt.Errorf("cannot load %s: %w", path, err)
+	}
+	if len(save.Campaigns) == 0 || len(save.Campaigns[0].Parties) == 0 {
+		return fmt.Errorf("cannot load %s: no parties saved", path)
+	}
+	for _, c := range save.Campaigns {
+		for _, pr := range c.Parties {
+			fillPartyRegistry(&pr.Registry)
+		}
+	}
+
+	// Each party's history was saved with it, so it still fits the party it is loaded with
+	Campaigns = save.Campaigns
+	nextCampaignID = save.NextCampaignID
+	activeParty = Campaigns[0].Parties[0]
+	reloadActiveParty()
+	// The journal picks every party up again from what was loaded
+	sinceSnapshot = map[*PartyRecord]int{}
+	for _, c := range Campaigns {
+		for _, pr := range c.Parties {
+			if err := SnapshotIfChanged(pr); err != nil {
+				log.Printf("warning: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// fillPartyRegistry replaces registries that were saved empty (and so loaded as nil) with empty maps,
+// and upgrades armor from older saves.
+func fillPartyRegistry(r *PartyRegistry) {
+	for _, a := range r.Armor {
+		upgradeArmor(a)
+	}
+	empty := newPartyRegistry()
+	if r.Items == nil {
+		r.Items = empty.Items
+	}
+	if r.Weapons == nil {
+		r.Weapons = empty.Weapons
+	}
+	if r.Armor == nil {
+		r.Armor = empty.Armor
+	}
+	if r.Shields == nil {
+		r.Shields = empty.Shields
+	}
+	if r.Jewelry == nil {
+		r.Jewelry = empty.Jewelry
+	}
+	if r.LimitedUseItems == nil {
+		r.LimitedUseItems = empty.LimitedUseItems
+	}
+	if r.Spellbooks == nil {
+		r.Spellbooks = empty.Spellbooks
+	}
+	if r.Encounters == nil {
+		r.Encounters = empty.Encounters
+	}
+}
+
+// upgradeArmor reads armor written before ArmorType had a name of its own. It was saved as Type,
+// which hid the item type and so loads as the item type.
+func upgradeArmor(a *Armor) {
+	if _, old := baseArmorClass[ArmorType(a.Item.Type)]; old {
+		a.ArmorType = ArmorType(a.Item.Type)
+		a.Item.Type = ItemArmor
+	}
+}
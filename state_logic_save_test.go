@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestLoadingArmorFromAnOlderSave(t *testing.T) {
+	resetState(t)
+	ch, armorID, _, _ := outfitFighter(t)
+	path := filepath.Join(t.TempDir(), "save.json")
+	if err := SaveCampaigns(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Older saves wrote the armor type as Type, in place of the item type
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = regexp.MustCompile(`"Type": "armor"`).ReplaceAll(data, []byte(`"Type": "chain"`))
+	data = regexp.MustCompile(`\s*"ArmorType": "chain",`).ReplaceAll(data, nil)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadCampaigns(path); err != nil {
+		t.Fatal(err)
+	}
+	a := ArmorByID[armorID]
+	if a.Type != ItemArmor || a.ArmorType != Chain {
+		t.Errorf("loaded armor has item type %q and armor type %q, want %q and %q", a.Type, a.ArmorType, ItemArmor, Chain)
+	}
+	if err := UnequipArmor(ch.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	if err := EquipArmor(ch.ID, armorID, &State); err != nil {
+		t.Errorf("armor from an older save cannot be worn: %v", err)
+	}
+}
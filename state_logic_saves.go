@@ -0,0 +1,100 @@
+package main
+
+// SAVING THROWS
+
+// SavingThrows holds the five B/X saving throw target numbers. Lower is better.
+type SavingThrows struct {
+	DeathPoison     int
+	Wands           int
+	ParalysisStone  int
+	BreathAttacks   int
+	SpellsRodsStaff int
+}
+
+// saveRow is one row of a class's saving throw table: the highest level it covers and the saves.
+type saveRow struct {
+	upTo  int
+	saves SavingThrows
+}
+
+var savingThrowTables = map[CharacterClass][]saveRow{
+	ClassCleric: {
+		{4, SavingThrows{11, 12, 14, 16, 15}},
+		{8, SavingThrows{9, 10, 12, 14, 12}},
+		{12, SavingThrows{6, 7, 9, 11, 9}},
+		{14, SavingThrows{3, 5, 7, 8, 7}},
+	},
+	ClassFighter: {
+		{3, SavingThrows{12, 13, 14, 15, 16}},
+		{6, SavingThrows{10, 11, 12, 13, 14}},
+		{9, SavingThrows{8, 9, 10, 10, 12}},
+		{12, SavingThrows{6, 7, 8, 8, 10}},
+		{14, SavingThrows{4, 5, 6, 5, 8}},
+	},
+	ClassMagicUser: {
+		{5, SavingThrows{13, 14, 13, 16, 15}},
+		{10, SavingThrows{11, 12, 11, 14, 12}},
+		{14, SavingThrows{8, 9, 8, 11, 8}},
+	},
+	ClassThief: {
+		{4, SavingThrows{13, 14, 13, 16, 15}},
+		{8, SavingThrows{12, 13, 11, 14, 13}},
+		{12, SavingThrows{10, 11, 9, 12, 10}},
+		{14, SavingThrows{8, 9, 7, 10, 8}},
+	},
+	ClassDwarf: {
+		{3, SavingThrows{8, 9, 10, 13, 12}},
+		{6, SavingThrows{6, 7, 8, 10, 10}},
+		{9, SavingThrows{4, 5, 6, 7, 8}},
+		{12, SavingThrows{2, 3, 4, 4, 6}},
+	},
+	ClassElf: {
+		{3, SavingThrows{12, 13, 13, 15, 15}},
+		{6, SavingThrows{10, 11, 11, 13, 12}},
+		{9, SavingThrows{8, 9, 9, 10, 10}},
+		{10, SavingThrows{6, 7, 8, 8, 8}},
+	},
+	ClassHalfling: {
+		{3, SavingThrows{8, 9, 10, 13, 12}},
+		{6, SavingThrows{6, 7, 8, 10, 10}},
+		{8, SavingThrows{4, 5, 6, 7, 8}},
+	},
+}
+
+// normalHumanSaves are used for characters without a class.
+var normalHumanSaves = SavingThrows{14, 15, 16, 17, 18}
+
+// BaseSavingThrows looks up the saving throws for a class and level.
+func BaseSavingThrows(class CharacterClass, level int) SavingThrows {
+	rows, ok := savingThrowTables[class]
+	if !ok {
+		return normalHumanSaves
+	}
+	for _, row := range rows {
+		if level <= row.upTo {
+			return row.saves
+		}
+	}
+	return rows[len(rows)-1].saves
+}
+
+// CharacterSavingThrows derives a character's saving throws, including carried jewelry such as a
+// Ring of Protection and any active effects.
+func CharacterSavingThrows(c *Character) SavingThrows {
+	s := BaseSavingThrows(c.Class, c.Level)
+	bonus := 0
+	for _, id := range c.Items {
+		if j, ok := JewelryByID[id]; ok {
+			bonus += j.SaveBonus
+		}
+	}
+	_, _, effectSaves := effectTotals(c)
+	bonus += effectSaves
+
+	s.DeathPoison -= bonus
+	s.Wands -= bonus
+	s.ParalysisStone -= bonus
+	s.BreathAttacks -= bonus
+	s.SpellsRodsStaff -= bonus
+	return s
+}
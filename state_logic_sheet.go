@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+)
+
+// CHARACTER SHEETS
+
+// CharacterSheet is everything printed on a B/X character sheet, worked out from a character.
+// The character page, the printable sheet and the PDF all show one.
+type CharacterSheet struct {
+	Name         string
+	Class        CharacterClass
+	Level        int
+	Alignment    Alignment
+	Status       CharacterStatus
+	Abilities    []SheetAbility
+	HitPoints    int
+	MaxHitPoints int
+	ArmorClass   int
+	THAC0        int
+	SavingThrows SavingThrows
+	AttackMatrix []AttackMatrixEntry
+	Equipment    []SheetItem
+	Gold         int
+	Experience   int
+	SpellLevels  []SheetSpellLevel // one per spell level the character can cast
+	KnownSpells  []string
+	Effects      []string
+	Employer     string // for retainers
+	Retainers    []string
+	Day          int // the game day the sheet was printed
+}
+
+// SheetAbility is an ability score with its modifier and what the modifier applies to.
+type SheetAbility struct {
+	Name     string
+	Score    int
+	Modifier int
+	Note     string
+}
+
+// AttackMatrixEntry is the d20 roll the character needs to hit one armor class.
+type AttackMatrixEntry struct {
+	ArmorClass int
+	Roll       int
+}
+
+// SheetItem is a piece of equipment. Equipped is "armor" or "shield" when worn.
+type SheetItem struct {
+	Name     string
+	Type     ItemType
+	Equipped string
+}
+
+// SheetSpellLevel is one level of a caster's spell slots and the spells memorized in them.
+type SheetSpellLevel struct {
+	Level     int
+	Slots     int
+	Memorized []string // cast spells are marked "(cast)"
+}
+
+// attackMatrixArmorClasses are the columns of the B/X attack matrix, from worst armor to best.
+var attackMatrixArmorClasses = []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0, -1, -2, -3}
+
+// BuildCharacterSheet works out a character's sheet with their equipment and effects applied.
+func BuildCharacterSheet(charID int, p *Party) (CharacterSheet, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return CharacterSheet{}, err
+	}
+	s := CharacterSheet{
+		Name:         ch.Name,
+		Class:        ch.Class,
+		Level:        ch.Level,
+		Alignment:    ch.Alignment,
+		Status:       ch.Status,
+		HitPoints:    ch.CurrentHitPoints,
+		MaxHitPoints: ch.MaximumHitPoints,
+		ArmorClass:   CharacterArmorClass(ch),
+		THAC0:        characterTHAC0(ch.Class, ch.Level),
+		SavingThrows: CharacterSavingThrows(ch),
+		Gold:         ch.Gold,
+		Experience:   ch.Experience,
+		Day:          p.Day,
+	}
+
+	s.Abilities = []SheetAbility{
+		{"Strength", ch.Strength, AbilityModifier(ch.Strength), "melee to-hit and damage"},
+		{"Intelligence", ch.Intelligence, AbilityModifier(ch.Intelligence), "languages"},
+		{"Wisdom", ch.Wisdom, AbilityModifier(ch.Wisdom), "saves against magic"},
+		{"Dexterity", ch.Dexterity, AbilityModifier(ch.Dexterity), "armor class and missile to-hit"},
+		{"Constitution", ch.Constitution, AbilityModifier(ch.Constitution), "hit points per level"},
+		{"Charisma", ch.Charisma, CharismaReactionModifier(ch.Charisma),
+			fmt.Sprintf("reactions; up to %d retainers at morale %d", MaxRetainers(ch.Charisma), RetainerMorale(ch.Charisma))},
+	}
+
+	for _, ac := range attackMatrixArmorClasses {
+		s.AttackMatrix = append(s.AttackMatrix, AttackMatrixEntry{ac, RollNeededToHit(s.THAC0, ac)})
+	}
+
+	for _, id := range ch.Items {
+		it, err := FindItemByID(id)
+		if err != nil {
+			continue
+		}
+		item := SheetItem{Name: it.Name, Type: it.Type}
+		switch id {
+		case ch.ArmorID:
+			item.Equipped = "armor"
+		case ch.ShieldID:
+			item.Equipped = "shield"
+		}
+		s.Equipment = append(s.Equipment, item)
+	}
+
+	for level := 1; level <= MaxSpellLevelAvailable(ch.Class, ch.Level); level++ {
+		sl := SheetSpellLevel{Level: level, Slots: GetSpellSlots(ch.Class, ch.Level, level)}
+		for _, ms := range ch.MemorizedSpells {
+			spell, ok := SpellsByID[ms.SpellID]
+			if !ok || spell.Level != level {
+				continue
+			}
+			name := SpellFormName(spell, ms.Reversed)
+			if ms.Cast {
+				name += " (cast)"
+			}
+			sl.Memorized = append(sl.Memorized, name)
+		}
+		s.SpellLevels = append(s.SpellLevels, sl)
+	}
+	for _, id := range KnownSpellIDs(ch) {
+		if spell, ok := SpellsByID[id]; ok {
+			s.KnownSpells = append(s.KnownSpells, fmt.Sprintf("%s (level %d)", spell.Name, spell.Level))
+		}
+	}
+
+	for _, e := range ch.Effects {
+		name := e.Name
+		if e.Source != "" {
+			name += " from " + e.Source
+		}
+		s.Effects = append(s.Effects, fmt.Sprintf("%s, %d rounds left", name, e.RemainingRounds))
+	}
+	if ch.Retainer != nil {
+		if employer, err := FindChar(p, ch.Retainer.EmployerID); err == nil {
+			s.Employer = employer.Name
+		}
+	}
+	for _, r := range Retainers(charID, p) {
+		s.Retainers = append(s.Retainers, r.Name)
+	}
+	return s, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 )
 
 // Spell utilities
@@ -49,31 +50,101 @@ var spellSlotTables = map[CharacterClass]map[int]map[int]int{
 type SpellValidationConfig struct {
 	EnforceKnown bool // cannot know more spells than you can cast
 	EnforceLevel bool // cannot learn spells of a higher level than you can cast (learning != memorizing)
+	RequireBook  bool // arcane spells can only be memorized from a spellbook the caster is carrying
+	StrictPrep   bool // memorized spells only change after a rest, and cast spells stay spent until reset
+	LawfulRevert bool // lawful clerics cannot cast the reversed form of a spell
 }
 
-var SpellRules = SpellValidationConfig{true, true}
+var SpellRules = SpellValidationConfig{true, true, true, true, true}
+
+// RegisterSpell adds a spell to the catalog.
+func RegisterSpell(s Spell) error {
+	if _, exists := SpellsByID[s.ID]; exists {
+		return fmt.Errorf("spell with ID %d already registered", s.ID)
+	}
+	if s.Reversible && s.ReversedName == "" {
+		return fmt.Errorf("reversible spell %s has no reversed name", s.Name)
+	}
+	SpellsByID[s.ID] = s
+	return nil
+}
+
+// SpellFormName returns the name of the normal or reversed form of a spell.
+func SpellFormName(s Spell, reversed bool) string {
+	if reversed && s.Reversible {
+		return s.ReversedName
+	}
+	return s.Name
+}
 
 // Add & Remove Known Spells
 
 func AddKnownSpell(c *Character, spellID int) error {
-	if err := CanLearnSpell(c, spellID); err != nil {
-		return err
-	}
-	c.KnownSpells = append(c.KnownSpells, spellID)
-	return nil
+	return learnSpell(c, spellID, SpellSource{})
 }
 
+// RemoveKnownSpell erases a spell from the character's spellbooks, or from KnownSpells if they have none.
 func RemoveKnownSpell(c *Character, spellID int) error {
 	for i, id := range c.KnownSpells {
 		if id == spellID {
 			c.KnownSpells = append(c.KnownSpells[:i], c.KnownSpells[i+1:]...)
+			c.SpellbookEntries = removeSpellbookEntry(c.SpellbookEntries, spellID)
+			return nil
+		}
+	}
+	for _, sb := range ownedSpellbooks(c) {
+		if spellbookContains(sb, spellID) {
+			sb.Entries = removeSpellbookEntry(sb.Entries, spellID)
 			return nil
 		}
 	}
 	return fmt.Errorf("spell %d not known", spellID)
 }
 
+// learnSpell writes a new spell into the first spellbook the character carries.
+// Characters who own no spellbook keep the spell in KnownSpells instead.
+func learnSpell(c *Character, spellID int, source SpellSource) error {
+	if err := CanLearnSpell(c, spellID); err != nil {
+		return err
+	}
+	if books := carriedSpellbooks(c); len(books) > 0 {
+		books[0].Entries = append(books[0].Entries, SpellbookEntry{SpellID: spellID, Source: source})
+		emitSpellLearned(c, spellID, source)
+		return nil
+	}
+	if len(ownedSpellbooks(c)) > 0 {
+		return fmt.Errorf("%s has no spellbook at hand", c.Name)
+	}
+	c.KnownSpells = append(c.KnownSpells, spellID)
+	if source.Type != SourceNone {
+		c.SpellbookEntries = append(c.SpellbookEntries, SpellbookEntry{SpellID: spellID, Source: source})
+	}
+	emitSpellLearned(c, spellID, source)
+	return nil
+}
+
+func emitSpellLearned(c *Character, spellID int, source SpellSource) {
+	name := SpellsByID[spellID].Name
+	if source.Type == SourceNone {
+		emit(EventSpellLearned, []int{c.ID}, nil, "%s learned %s", c.Name, name)
+		return
+	}
+	emit(EventSpellLearned, []int{c.ID}, []int{source.ItemID}, "%s copied %s from %s", c.Name, name, source.ItemName)
+}
+
 func AddMemorizedSpell(c *Character, spellID int) error {
+	return MemorizeSpellForm(c, spellID, false)
+}
+
+// MemorizeSpellForm memorizes the normal or reversed form of a spell.
+// Only arcane casters fix the form at memorization; divine spells are memorized in their normal
+// form and reversed when cast.
+func MemorizeSpellForm(c *Character, spellID int, reversed bool) error {
+	// Spells are prepared after resting, not mid-dungeon
+	if err := checkPreparationOpen(c); err != nil {
+		return err
+	}
+
 	// Check that the spell exists
 	spell, ok := SpellsByID[spellID]
 	if !ok {
@@ -85,19 +156,39 @@ func AddMemorizedSpell(c *Character, spellID int) error {
 		return err
 	}
 
+	// Arcane spells must be studied from a spellbook at hand
+	if err := checkSpellbookAtHand(c, spell); err != nil {
+		return err
+	}
+
 	// Must not exceed per-level memorized count
 	if err := checkMemorizedSpellSlotLimit(c, spell.Level); err != nil {
 		return err
 	}
 
-	c.MemorizedSpells = append(c.MemorizedSpells, MemorizedSpell{SpellID: spellID})
+	// Reversed forms only exist for reversible spells
+	if reversed && !spell.Reversible {
+		return fmt.Errorf("%s is not reversible", spell.Name)
+	}
+	if spell.Type == SpellDivine {
+		reversed = false
+	}
+
+	c.MemorizedSpells = append(c.MemorizedSpells, MemorizedSpell{SpellID: spellID, Reversed: reversed})
+	recordPreparedSpells(c)
+	emit(EventSpellMemorized, []int{c.ID}, nil, "%s memorized %s", c.Name, SpellFormName(spell, reversed))
 	return nil
 }
 
 func RemoveMemorizedSpell(c *Character, spellID int) error {
+	if err := checkPreparationOpen(c); err != nil {
+		return err
+	}
 	for i, ms := range c.MemorizedSpells {
 		if ms.SpellID == spellID {
 			c.MemorizedSpells = append(c.MemorizedSpells[:i], c.MemorizedSpells[i+1:]...)
+			recordPreparedSpells(c)
+			emit(EventSpellMemorized, []int{c.ID}, nil, "%s forgot %s", c.Name, SpellsByID[spellID].Name)
 			return nil
 		}
 	}
@@ -127,7 +218,7 @@ func checkIfCharacterMayCast(c *Character, spellType SpellType) error {
 
 // Determine if the spell is already known (for the purpose of not learning duplicate spells)
 func checkIfAlreadyKnown(c *Character, spellID int, spellName string) error {
-	for _, id := range c.KnownSpells {
+	for _, id := range learnedSpellIDs(c) {
 		if id == spellID {
 			return fmt.Errorf("spell %s already known", spellName)
 		}
@@ -138,7 +229,7 @@ func checkIfAlreadyKnown(c *Character, spellID int, spellName string) error {
 // Determine how many spells of this level are already known
 func countKnownSpellsAtLevel(c *Character, level int) int {
 	count := 0
-	for _, id := range c.KnownSpells {
+	for _, id := range learnedSpellIDs(c) {
 		s, ok := SpellsByID[id]
 		if !ok {
 			continue
@@ -172,9 +263,10 @@ func checkSpellSlotLimit(c *Character, spellLevel int) error {
 
 // Memorized Spell Helpers
 
-// Determine if the spell is known (for the purpose of memorization)
+// Determine if the spell has been learned (for the purpose of memorization; checkSpellbookAtHand
+// then decides whether the book it is in is at hand)
 func checkSpellIsKnown(c *Character, spellID int) error {
-	for _, id := range c.KnownSpells {
+	for _, id := range learnedSpellIDs(c) {
 		if id == spellID {
 			return nil
 		}
@@ -203,7 +295,7 @@ func checkMemorizedSpellSlotLimit(c *Character, spellLevel int) error {
 }
 
 func isSpellKnown(c *Character, spellID int) bool {
-	for _, id := range c.KnownSpells {
+	for _, id := range learnedSpellIDs(c) {
 		if id == spellID {
 			return true
 		}
@@ -251,7 +343,7 @@ func ValidateSpells(c *Character) []error {
 	seen := make(map[int]bool)        // track duplicates
 	countByLevel := make(map[int]int) // for slot limits
 
-	for _, id := range c.KnownSpells {
+	for _, id := range learnedSpellIDs(c) {
 		spell, ok := SpellsByID[id]
 		if !ok {
 			errs = append(errs, fmt.Errorf("spell %d does not exist", id))
@@ -322,19 +414,73 @@ func MaxSpellLevelAvailable(class CharacterClass, level int) int {
 }
 
 func CastMemorizedSpell(c *Character, spellID int) error {
+	if err := checkCharacterCanAct(c); err != nil {
+		return err
+	}
 	for i := range c.MemorizedSpells {
 		if c.MemorizedSpells[i].SpellID == spellID && !c.MemorizedSpells[i].Cast {
 			c.MemorizedSpells[i].Cast = true
+			c.PreparingSpells = false // casting ends the day's preparation
+			emit(EventSpellCast, []int{c.ID}, nil, "%s cast %s", c.Name, SpellsByID[spellID].Name)
 			return nil
 		}
 	}
 	return fmt.Errorf("no uncast memorized copy of spell %d found", spellID)
 }
 
+// CastMemorizedSpellForm casts the normal or reversed form of a memorized spell.
+// Arcane casters need a copy memorized in that form. Divine casters reverse the spell as they
+// cast it, subject to their alignment.
+func CastMemorizedSpellForm(c *Character, spellID int, reversed bool) error {
+	if err := checkCharacterCanAct(c); err != nil {
+		return err
+	}
+	spell, err := getSpellIfExists(spellID)
+	if err != nil {
+		return err
+	}
+	if reversed && !spell.Reversible {
+		return fmt.Errorf("%s is not reversible", spell.Name)
+	}
+	if spell.Type == SpellDivine {
+		if err := checkReversalAllowed(c, spell, reversed); err != nil {
+			return err
+		}
+	}
+
+	for i := range c.MemorizedSpells {
+		ms := &c.MemorizedSpells[i]
+		if ms.SpellID != spellID || ms.Cast {
+			continue
+		}
+		if spell.Type == SpellArcane && ms.Reversed != reversed {
+			continue
+		}
+		ms.Cast = true
+		ms.Reversed = reversed
+		c.PreparingSpells = false // casting ends the day's preparation
+		emit(EventSpellCast, []int{c.ID}, nil, "%s cast %s", c.Name, SpellFormName(spell, reversed))
+		return nil
+	}
+	return fmt.Errorf("no uncast memorized copy of %s found", SpellFormName(spell, reversed))
+}
+
+// Determine if a cleric's alignment lets them cast this form of a spell
+func checkReversalAllowed(c *Character, spell Spell, reversed bool) error {
+	if reversed && SpellRules.LawfulRevert && c.Alignment == AlignmentLawful {
+		return fmt.Errorf("lawful clerics cannot cast %s", spell.ReversedName)
+	}
+	return nil
+}
+
 func UncastMemorizedSpell(c *Character, spellID int) error {
+	if SpellRules.StrictPrep {
+		return fmt.Errorf("cast spells stay spent until the next rest")
+	}
 	for i := range c.MemorizedSpells {
 		if c.MemorizedSpells[i].SpellID == spellID && c.MemorizedSpells[i].Cast {
 			c.MemorizedSpells[i].Cast = false
+			emit(EventSpellCast, []int{c.ID}, nil, "%s's casting of %s was taken back", c.Name, SpellsByID[spellID].Name)
 			return nil
 		}
 	}
@@ -344,6 +490,10 @@ func UncastMemorizedSpell(c *Character, spellID int) error {
 func ResetAllMemorizedSpells(c *Character) {
 	for i := range c.MemorizedSpells {
 		c.MemorizedSpells[i].Cast = false
+		// Divine spells are only reversed for the cast itself
+		if SpellsByID[c.MemorizedSpells[i].SpellID].Type == SpellDivine {
+			c.MemorizedSpells[i].Reversed = false
+		}
 	}
 }
 
@@ -382,3 +532,290 @@ func ValidateMemorizedSpells(c *Character) []error {
 
 	return errs
 }
+
+// COPYING SPELLS
+
+// SpellCopyConfig holds optional house rules for copying spells from scrolls and captured spellbooks.
+// By the book, copying costs nothing but the scroll's charge.
+// Days of study advance the campaign clock: copying a spell rests the whole party for those days,
+// exactly as RestParty with RestCustom would, and SpellCopyResult.Rest reports that rest.
+type SpellCopyConfig struct {
+	GoldPerLevel int // gold charged per spell level copied. 0 disables the cost
+	DaysPerLevel int // days of study per spell level copied, which the party spends resting. 0 disables the cost
+}
+
+var SpellCopyRules = SpellCopyConfig{0, 0}
+
+// SpellCopyResult reports what a copied spell cost the caster.
+type SpellCopyResult struct {
+	SpellID   int
+	GoldSpent int
+	DaysSpent int
+	Rest      []RestResult // the party's rest while the caster studied, if it took any days
+}
+
+// LearnSpellFromScroll copies a spell scroll carried by the character into their known spells.
+// The scroll spends a charge. Like every limited use item it is kept once it has none left.
+func LearnSpellFromScroll(charID, itemID int, p *Party) (SpellCopyResult, error) {
+	var result SpellCopyResult
+	err := Transact(p, func() error {
+		r, err := learnSpellFromScroll(charID, itemID, p)
+		result = r
+		return err
+	})
+	return result, err
+}
+
+func learnSpellFromScroll(charID, itemID int, p *Party) (SpellCopyResult, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return SpellCopyResult{}, err
+	}
+	lu, ok := LimitedUseItemsByID[itemID]
+	if !ok {
+		return SpellCopyResult{}, fmt.Errorf("limited-use item %d not found", itemID)
+	}
+	if lu.Location != LocationCharacter || lu.HolderID != charID {
+		return SpellCopyResult{}, fmt.Errorf("item not owned by character")
+	}
+	if lu.SpellID == 0 {
+		return SpellCopyResult{}, fmt.Errorf("%s is not a spell scroll", lu.Name)
+	}
+	if lu.Charges <= 0 {
+		return SpellCopyResult{}, fmt.Errorf("%s has no charges remaining", lu.Name)
+	}
+
+	source := SpellSource{Type: SourceScroll, ItemID: lu.ID, ItemName: lu.Name}
+	result, err := copySpell(ch, lu.SpellID, source, p)
+	if err != nil {
+		return SpellCopyResult{}, err
+	}
+
+	lu.Charges--
+	return result, nil
+}
+
+// LearnSpellFromSpellbook copies one spell out of a spellbook carried by the character.
+// The spellbook is left intact.
+func LearnSpellFromSpellbook(charID, itemID, spellID int, p *Party) (SpellCopyResult, error) {
+	var result SpellCopyResult
+	err := Transact(p, func() error {
+		r, err := learnSpellFromSpellbook(charID, itemID, spellID, p)
+		result = r
+		return err
+	})
+	return result, err
+}
+
+func learnSpellFromSpellbook(charID, itemID, spellID int, p *Party) (SpellCopyResult, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return SpellCopyResult{}, err
+	}
+	sb, ok := SpellbooksByID[itemID]
+	if !ok {
+		return SpellCopyResult{}, fmt.Errorf("spellbook %d not found", itemID)
+	}
+	if sb.Location != LocationCharacter || sb.HolderID != charID {
+		return SpellCopyResult{}, fmt.Errorf("item not owned by character")
+	}
+	if !spellbookContains(sb, spellID) {
+		return SpellCopyResult{}, fmt.Errorf("spell %d is not in %s", spellID, sb.Name)
+	}
+
+	source := SpellSource{Type: SourceSpellbook, ItemID: sb.ID, ItemName: sb.Name}
+	return copySpell(ch, spellID, source, p)
+}
+
+// copySpell checks CanLearnSpell, charges any house rule costs, and records where the spell came from.
+// Days of study advance the clock with a rest for the whole party; see SpellCopyConfig.
+func copySpell(c *Character, spellID int, source SpellSource, p *Party) (SpellCopyResult, error) {
+	if err := CanLearnSpell(c, spellID); err != nil {
+		return SpellCopyResult{}, err
+	}
+	spell := SpellsByID[spellID]
+	result := SpellCopyResult{
+		SpellID:   spellID,
+		GoldSpent: SpellCopyRules.GoldPerLevel * spell.Level,
+		DaysSpent: SpellCopyRules.DaysPerLevel * spell.Level,
+	}
+	if c.Gold < result.GoldSpent {
+		return SpellCopyResult{}, fmt.Errorf("copying %s costs %d gold (has %d)", spell.Name, result.GoldSpent, c.Gold)
+	}
+
+	if err := learnSpell(c, spellID, source); err != nil {
+		return SpellCopyResult{}, err
+	}
+	c.Gold -= result.GoldSpent
+	if result.DaysSpent > 0 {
+		rest, err := RestParty(p, RestCustom, result.DaysSpent)
+		if err != nil {
+			return SpellCopyResult{}, err
+		}
+		result.Rest = rest
+	}
+	return result, nil
+}
+
+func removeSpellbookEntry(entries []SpellbookEntry, spellID int) []SpellbookEntry {
+	out := entries[:0]
+	for _, e := range entries {
+		if e.SpellID != spellID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func spellbookContains(sb *Spellbook, spellID int) bool {
+	for _, e := range sb.Entries {
+		if e.SpellID == spellID {
+			return true
+		}
+	}
+	return false
+}
+
+// SPELLBOOKS
+
+// KnownSpellIDs returns the spells the character can study: divine spells in KnownSpells, and
+// arcane spells from the spellbooks they carry. Without SpellRules.RequireBook, every spell they
+// have learned counts, wherever the book is kept.
+func KnownSpellIDs(c *Character) []int {
+	if !SpellRules.RequireBook {
+		return learnedSpellIDs(c)
+	}
+	ids := []int{}
+	for _, id := range c.KnownSpells {
+		if SpellsByID[id].Type != SpellArcane {
+			ids = append(ids, id)
+		}
+	}
+	for _, sb := range carriedSpellbooks(c) {
+		for _, e := range sb.Entries {
+			if !hasID(ids, e.SpellID) {
+				ids = append(ids, e.SpellID)
+			}
+		}
+	}
+	return ids
+}
+
+// learnedSpellIDs returns every spell the character has learned: KnownSpells plus the contents of
+// each spellbook they own, wherever it is kept. A book left in storage still counts against how
+// many spells they may learn.
+func learnedSpellIDs(c *Character) []int {
+	ids := append([]int{}, c.KnownSpells...)
+	for _, sb := range ownedSpellbooks(c) {
+		for _, e := range sb.Entries {
+			if !hasID(ids, e.SpellID) {
+				ids = append(ids, e.SpellID)
+			}
+		}
+	}
+	return ids
+}
+
+// ownedSpellbooks returns the spellbooks written by the character, ordered by item ID.
+func ownedSpellbooks(c *Character) []*Spellbook {
+	var books []*Spellbook
+	for _, sb := range SpellbooksByID {
+		if sb.OwnerID == c.ID {
+			books = append(books, sb)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].ID < books[j].ID })
+	return books
+}
+
+// carriedSpellbooks returns the character's own spellbooks that are in their inventory.
+func carriedSpellbooks(c *Character) []*Spellbook {
+	var books []*Spellbook
+	for _, sb := range ownedSpellbooks(c) {
+		if sb.Location == LocationCharacter && sb.HolderID == c.ID && hasID(c.Items, sb.ID) {
+			books = append(books, sb)
+		}
+	}
+	return books
+}
+
+// Determine if an arcane spell can be studied from a spellbook the character is carrying
+func checkSpellbookAtHand(c *Character, spell Spell) error {
+	if !SpellRules.RequireBook || spell.Type != SpellArcane {
+		return nil
+	}
+	for _, sb := range carriedSpellbooks(c) {
+		if spellbookContains(sb, spell.ID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s needs to carry a spellbook containing %s to memorize it", c.Name, spell.Name)
+}
+
+// BindSpellbook writes the character's KnownSpells into a new spellbook in their inventory.
+// From then on the spells can be lost, stolen, or left in storage along with the book.
+func BindSpellbook(charID int, name string, p *Party) (*Spellbook, error) {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ch.Items) >= 10 {
+		return nil, fmt.Errorf("inventory full")
+	}
+
+	sb := NewSpellbook(name, charID, LocationNone)
+	for _, id := range ch.KnownSpells {
+		entry := SpellbookEntry{SpellID: id}
+		for _, e := range ch.SpellbookEntries {
+			if e.SpellID == id {
+				entry.Source = e.Source
+			}
+		}
+		sb.Entries = append(sb.Entries, entry)
+	}
+	if err := MoveItemToCharacter(sb.ID, charID, p); err != nil {
+		UnregisterItem(sb.ID)
+		return nil, err
+	}
+
+	ch.KnownSpells = []int{}
+	ch.SpellbookEntries = []SpellbookEntry{}
+	emit(EventSpellLearned, []int{charID}, []int{sb.ID}, "%s bound their spells into %s", ch.Name, sb.Name)
+	return sb, nil
+}
+
+// PREPARATION
+
+// OpenSpellPreparation starts a preparation session for the given game day.
+// It is called when a character finishes a rest; the memorized spells carried over from
+// the previous day become the starting point for the new day's list.
+func OpenSpellPreparation(c *Character, day int) {
+	c.PreparingSpells = true
+	c.PreparedSpellLog = append(c.PreparedSpellLog, PreparedSpellList{Day: day})
+	recordPreparedSpells(c)
+}
+
+// FinishSpellPreparation locks in the character's memorized spells until their next rest.
+func FinishSpellPreparation(c *Character) {
+	c.PreparingSpells = false
+}
+
+// Determine if the character may change their memorized spells right now
+func checkPreparationOpen(c *Character) error {
+	if SpellRules.StrictPrep && !c.PreparingSpells {
+		return fmt.Errorf("%s can only prepare spells after a rest", c.Name)
+	}
+	return nil
+}
+
+// recordPreparedSpells copies the memorized spell list into today's entry in PreparedSpellLog.
+func recordPreparedSpells(c *Character) {
+	if !c.PreparingSpells || len(c.PreparedSpellLog) == 0 {
+		return
+	}
+	ids := make([]int, 0, len(c.MemorizedSpells))
+	for _, ms := range c.MemorizedSpells {
+		ids = append(ids, ms.SpellID)
+	}
+	c.PreparedSpellLog[len(c.PreparedSpellLog)-1].SpellIDs = ids
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// testSpells are first level magic-user spells for the tests, numbered clear of the catalog.
+var testSpells = []Spell{
+	{ID: 9001, Name: "Sleep", Level: 1, Type: SpellArcane},
+	{ID: 9002, Name: "Magic Missile", Level: 1, Type: SpellArcane},
+}
+
+// newMagicUser adds a first level magic-user who has learned the first test spell, and returns it.
+func newMagicUser(t *testing.T) (*Character, int) {
+	t.Helper()
+	for _, s := range testSpells {
+		SpellsByID[s.ID] = s
+	}
+	ch := AddCharacter(&State, "Mirel")
+	c, _ := FindChar(&State, ch.ID)
+	c.Class = ClassMagicUser
+	c.Spellcasting = []SpellType{SpellArcane}
+	c.Intelligence = 16
+	if err := AddKnownSpell(c, testSpells[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	return c, testSpells[0].ID
+}
+
+func TestMemorizingNeedsACarriedSpellbook(t *testing.T) {
+	resetState(t)
+	c, spellID := newMagicUser(t)
+
+	if err := AddMemorizedSpell(c, spellID); err == nil {
+		t.Fatal("a magic-user without a spellbook should not be able to memorize")
+	}
+
+	sb, err := BindSpellbook(c.ID, "Mirel's book", &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ = FindChar(&State, c.ID)
+	if !hasID(KnownSpellIDs(c), spellID) {
+		t.Error("spells in a carried book should be known")
+	}
+
+	if err := MoveItemToStorage(sb.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = FindChar(&State, c.ID)
+	if hasID(KnownSpellIDs(c), spellID) {
+		t.Error("spells in a stored book should not be known in the dungeon")
+	}
+	if err := AddMemorizedSpell(c, spellID); err == nil {
+		t.Error("memorizing should need the book at hand")
+	}
+	if err := AddKnownSpell(c, spellID); err == nil {
+		t.Error("a spell in a stored book is still learned and cannot be learned again")
+	}
+
+	if err := MoveItemToCharacter(sb.ID, c.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	c, _ = FindChar(&State, c.ID)
+	if err := AddMemorizedSpell(c, spellID); err != nil {
+		t.Errorf("memorizing from a carried book: %v", err)
+	}
+}
+
+func TestCopyingASpellSpendsItsDays(t *testing.T) {
+	resetState(t)
+	c, _ := newMagicUser(t)
+	c.Level = 5 // room for a second first level spell
+	saved := SpellCopyRules
+	SpellCopyRules = SpellCopyConfig{0, 2}
+	t.Cleanup(func() { SpellCopyRules = saved })
+
+	scroll := NewLimitedUseItem("Scroll", 1, true, false, false, LocationStorage)
+	scroll.SpellID = testSpells[1].ID
+	if err := MoveItemToCharacter(scroll.ID, c.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+
+	day := State.Day
+	result, err := LearnSpellFromScroll(c.ID, scroll.ID, &State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DaysSpent != 2 || State.Day != day+2 {
+		t.Errorf("copying took %d days and the party is on day %d, want 2 days and day %d", result.DaysSpent, State.Day, day+2)
+	}
+	if len(result.Rest) != 1 {
+		t.Errorf("got %d rest results, want one for the caster", len(result.Rest))
+	}
+}
+
+func TestLearningSpellsThroughTheAPI(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	c, _ := newMagicUser(t)
+	c.Level = 5
+
+	scroll := NewLimitedUseItem("Scroll", 1, true, false, false, LocationStorage)
+	scroll.SpellID = testSpells[1].ID
+	captured := NewSpellbook("Captured book", 0, LocationStorage)
+	captured.Entries = append(captured.Entries, SpellbookEntry{SpellID: testSpells[1].ID})
+	for _, id := range []int{scroll.ID, captured.ID} {
+		if err := MoveItemToCharacter(id, c.ID, &State); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := fmt.Sprintf("/api/characters/%d/spells/learn", c.ID)
+	apiRequest(t, e, "POST", path, fmt.Sprintf(`{"ItemID":%d}`, scroll.ID))
+	c, _ = FindChar(&State, c.ID)
+	if !hasID(learnedSpellIDs(c), testSpells[1].ID) {
+		t.Error("the scroll's spell should be learned")
+	}
+	if _, ok := LimitedUseItemsByID[scroll.ID]; !ok || scroll.Charges != 0 {
+		t.Errorf("a spent scroll should be kept at 0 charges, has %d", scroll.Charges)
+	}
+
+	body := fmt.Sprintf(`{"ItemID":%d,"SpellID":%d}`, captured.ID, testSpells[1].ID)
+	if rec := apiResponse(e, "POST", path, body); rec.Code != 400 {
+		t.Errorf("learning a known spell again: status %d, want 400", rec.Code)
+	}
+}
+
+func TestBindingASpellbookThroughTheAPI(t *testing.T) {
+	resetState(t)
+	e := echo.New()
+	registerAPIRoutes(e)
+	c, spellID := newMagicUser(t)
+
+	memorize := fmt.Sprintf("/api/characters/%d/spells/memorize", c.ID)
+	body := fmt.Sprintf(`{"SpellID":%d}`, spellID)
+	if rec := apiResponse(e, "POST", memorize, body); rec.Code != 400 {
+		t.Fatalf("memorizing without a spellbook: status %d, want 400", rec.Code)
+	}
+	apiRequest(t, e, "POST", fmt.Sprintf("/api/characters/%d/spellbooks", c.ID), "")
+	apiRequest(t, e, "POST", memorize, body)
+
+	c, _ = FindChar(&State, c.ID)
+	if len(ownedSpellbooks(c)) != 1 || ownedSpellbooks(c)[0].Name != "Mirel's spellbook" {
+		t.Errorf("want one spellbook named after Mirel, got %+v", ownedSpellbooks(c))
+	}
+	if len(c.MemorizedSpells) != 1 || c.MemorizedSpells[0].SpellID != spellID {
+		t.Errorf("memorized spells are %v, want %d", c.MemorizedSpells, spellID)
+	}
+}
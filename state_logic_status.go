@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+)
+
+// STATUS
+
+// DeathConfig holds the optional house rules for dying.
+// By the book, a character reduced to 0 hit points is dead.
+type DeathConfig struct {
+	DeathsDoor bool // characters at 0 hit points or below are unconscious rather than dead
+	DeadAt     int  // with DeathsDoor, the (negative) hit point total at which a character dies
+}
+
+var DeathRules = DeathConfig{false, -10}
+
+// lowestHitPoints is the lowest CurrentHitPoints a character can have under the current rules.
+func lowestHitPoints() int {
+	if DeathRules.DeathsDoor {
+		return DeathRules.DeadAt
+	}
+	return 0
+}
+
+// UpdateCharacterStatus flags a character as unconscious or dead from their current hit points,
+// and wakes unconscious characters who have been healed. Characters whose hit points have never been
+// rolled (MaximumHitPoints 0) are left alone. Dead characters stay dead until the referee says otherwise.
+func UpdateCharacterStatus(c *Character) {
+	if c.MaximumHitPoints <= 0 || c.Status == StatusDead {
+		return
+	}
+	before := c.Status
+	defer func() {
+		if c.Status != before {
+			emit(EventStatusChanged, []int{c.ID}, nil, "%s is now %s", c.Name, c.Status)
+		}
+	}()
+	switch {
+	case DeathRules.DeathsDoor && c.CurrentHitPoints <= DeathRules.DeadAt:
+		c.Status = StatusDead
+	case !DeathRules.DeathsDoor && c.CurrentHitPoints <= 0:
+		c.Status = StatusDead
+	case c.CurrentHitPoints <= 0:
+		c.Status = StatusUnconscious
+	case c.Status == StatusUnconscious:
+		c.Status = StatusAlive
+	}
+}
+
+// SetCharacterStatus lets the referee apply a status directly, e.g. petrification or a Raise Dead.
+func SetCharacterStatus(charID int, status CharacterStatus, p *Party) error {
+	ch, err := FindChar(p, charID)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case StatusAlive, StatusUnconscious, StatusDead, StatusPetrified, StatusParalyzed, StatusPoisoned:
+	default:
+		return fmt.Errorf("invalid status: %q", status)
+	}
+	ch.Status = status
+	emit(EventStatusChanged, []int{charID}, nil, "%s is now %s", ch.Name, status)
+	return nil
+}
+
+// IsDead reports whether a character is dead. Dead characters keep their inventory so it can be looted.
+func IsDead(c *Character) bool {
+	return c.Status == StatusDead
+}
+
+// IsIncapacitated reports whether a character is unable to act: unconscious, dead, petrified, or paralyzed.
+func IsIncapacitated(c *Character) bool {
+	switch c.Status {
+	case StatusUnconscious, StatusDead, StatusPetrified, StatusParalyzed:
+		return true
+	}
+	return false
+}
+
+// Determine if the character is able to act at all
+func checkCharacterCanAct(c *Character) error {
+	if IsIncapacitated(c) {
+		return fmt.Errorf("%s is %s", c.Name, c.Status)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+)
+
+// TRANSACTIONS
+
+// Transact runs fn as a single change to the party and registries. If fn returns an error, every
+// change it made is undone, its events are dropped, and the party and registries are left
+// exactly as they were.
+// Transactions may be nested; each one only undoes its own work.
+func Transact(p *Party, fn func() error) error {
+	tx := beginTransaction(p)
+	emitted := len(pendingEvents)
+	if err := fn(); err != nil {
+		pendingEvents = pendingEvents[:emitted]
+		tx.rollback(p)
+		return err
+	}
+	return nil
+}
+
+// transaction is what a rollback needs: deep copies of the party and of every record, and the
+// characters slice and registry maps that were in use, so they can be put back where they were.
+type transaction struct {
+	party      Party
+	characters []Character
+	registry   PartyRegistry // the maps and counters in use
+	pointers   PartyRegistry // which record each ID held
+	records    PartyRegistry // copies of those records
+}
+
+func beginTransaction(p *Party) transaction {
+	r := currentRegistry()
+	return transaction{
+		party:      deepCopy(*p),
+		characters: p.Characters,
+		registry:   r,
+		pointers: PartyRegistry{
+			Items:           maps.Clone(r.Items),
+			Weapons:         maps.Clone(r.Weapons),
+			Armor:           maps.Clone(r.Armor),
+			Shields:         maps.Clone(r.Shields),
+			Jewelry:         maps.Clone(r.Jewelry),
+			LimitedUseItems: maps.Clone(r.LimitedUseItems),
+			Spellbooks:      maps.Clone(r.Spellbooks),
+			Encounters:      maps.Clone(r.Encounters),
+		},
+		records: deepCopy(r),
+	}
+}
+
+// rollback puts the party and registries back as they were. Characters are copied back into the
+// slice they were in and records into the structs they were in, so a *Character, *Item or
+// *Encounter taken before the transaction still points at the party's own.
+func (tx transaction) rollback(p *Party) {
+	*p = tx.party
+	copy(tx.characters, p.Characters)
+	p.Characters = tx.characters
+
+	restoreRecords(tx.registry.Items, tx.pointers.Items, tx.records.Items)
+	restoreRecords(tx.registry.Weapons, tx.pointers.Weapons, tx.records.Weapons)
+	restoreRecords(tx.registry.Armor, tx.pointers.Armor, tx.records.Armor)
+	restoreRecords(tx.registry.Shields, tx.pointers.Shields, tx.records.Shields)
+	restoreRecords(tx.registry.Jewelry, tx.pointers.Jewelry, tx.records.Jewelry)
+	restoreRecords(tx.registry.LimitedUseItems, tx.pointers.LimitedUseItems, tx.records.LimitedUseItems)
+	restoreRecords(tx.registry.Spellbooks, tx.pointers.Spellbooks, tx.records.Spellbooks)
+	restoreRecords(tx.registry.Encounters, tx.pointers.Encounters, tx.records.Encounters)
+	loadRegistry(tx.registry)
+}
+
+// restoreRecords empties a registry map and refills it with the records it held, as they were.
+func restoreRecords[T any](m, pointers, records map[int]*T) {
+	clear(m)
+	for id, ptr := range pointers {
+		*ptr = *records[id]
+		m[id] = ptr
+	}
+}
+
+// deepCopy copies v and everything it refers to, so nothing done to one is seen in the other.
+func deepCopy[T any](v T) T {
+	var out T
+	copyValue(reflect.ValueOf(&out).Elem(), reflect.ValueOf(v))
+	return out
+}
+
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if !src.IsNil() {
+			p := reflect.New(src.Type().Elem())
+			copyValue(p.Elem(), src.Elem())
+			dst.Set(p)
+		}
+	case reflect.Interface:
+		if !src.IsNil() {
+			v := reflect.New(src.Elem().Type()).Elem()
+			copyValue(v, src.Elem())
+			dst.Set(v)
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+			for i := range src.Len() {
+				copyValue(s.Index(i), src.Index(i))
+			}
+			dst.Set(s)
+		}
+	case reflect.Map:
+		if !src.IsNil() {
+			m := reflect.MakeMapWithSize(src.Type(), src.Len())
+			for iter := src.MapRange(); iter.Next(); {
+				v := reflect.New(src.Type().Elem()).Elem()
+				copyValue(v, iter.Value())
+				m.SetMapIndex(iter.Key(), v)
+			}
+			dst.Set(m)
+		}
+	case reflect.Struct:
+		// Unexported fields, like a time.Time's location, are shared
+		dst.Set(src)
+		for i := range src.NumField() {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Array:
+		for i := range src.Len() {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// SNAPSHOTS
+
+// partySnapshot is a complete copy of a party and the registries, as the journal and history
+// write them out.
+type partySnapshot struct {
+	Party    Party
+	Registry PartyRegistry
+}
+
+// takeSnapshot writes out the party and the registries.
+func takeSnapshot(p *Party) (json.RawMessage, error) {
+	return json.Marshal(partySnapshot{*p, currentRegistry()})
+}
+
+// restoreSnapshot puts the party and the registries back as they were in a snapshot.
+func restoreSnapshot(p *Party, raw json.RawMessage) error {
+	var snap partySnapshot
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		return err
+	}
+	fillPartyRegistry(&snap.Registry)
+	*p = snap.Party
+	loadRegistry(snap.Registry)
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// outfitFighter adds a fighter wearing chain mail and carrying a sword, with a shield in storage.
+func outfitFighter(t *testing.T) (ch Character, armorID, swordID, shieldID int) {
+	t.Helper()
+	ch = AddCharacter(&State, "Aldric")
+	c, _ := FindChar(&State, ch.ID)
+	c.Class = ClassFighter
+	armorID = NewArmor("Chain Mail", Chain, 0, LocationStorage).ID
+	swordID = NewWeapon("Sword", 8, 0, true, false, false, false, LocationStorage).ID
+	shieldID = NewShield("Shield", 0, LocationStorage).ID
+	for _, id := range []int{armorID, swordID} {
+		if err := MoveItemToCharacter(id, ch.ID, &State); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := EquipArmor(ch.ID, armorID, &State); err != nil {
+		t.Fatal(err)
+	}
+	return ch, armorID, swordID, shieldID
+}
+
+// snapshotState copies the party and registries as JSON, for comparing before and after.
+func snapshotState(t *testing.T) []byte {
+	t.Helper()
+	data, err := json.Marshal(partySnapshot{State, currentRegistry()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// assertUnchanged fails unless the party and registries match the snapshot taken before.
+func assertUnchanged(t *testing.T, before []byte) {
+	t.Helper()
+	after := snapshotState(t)
+	if !bytes.Equal(before, after) {
+		t.Errorf("party changed after rollback:\nbefore %s\nafter  %s", before, after)
+	}
+}
+
+func TestTransactRollsBackInjectedFailure(t *testing.T) {
+	resetState(t)
+	ch, armorID, swordID, shieldID := outfitFighter(t)
+	before := snapshotState(t)
+	events := len(pendingEvents)
+
+	injected := errors.New("injected failure")
+	err := Transact(&State, func() error {
+		AddCharacter(&State, "Mirel")
+		if err := MoveItemToParty(swordID, &State); err != nil {
+			return err
+		}
+		if err := MoveItemToCharacter(shieldID, ch.ID, &State); err != nil {
+			return err
+		}
+		NewArmor("Plate Mail", Plate, 1, LocationStorage)
+		c, _ := FindChar(&State, ch.ID)
+		c.Gold = 500
+		return injected
+	})
+	if !errors.Is(err, injected) {
+		t.Fatalf("Transact returned %v, want the injected failure", err)
+	}
+	assertUnchanged(t, before)
+	if len(pendingEvents) != events {
+		t.Errorf("rolled back transaction left %d events, want %d", len(pendingEvents), events)
+	}
+
+	if err := UnequipArmor(ch.ID, &State); err != nil {
+		t.Fatal(err)
+	}
+	if err := EquipArmor(ch.ID, armorID, &State); err != nil {
+		t.Errorf("armor cannot be equipped after rollback: %v", err)
+	}
+}
+
+func TestNestedTransactUndoesOnlyItsOwnWork(t *testing.T) {
+	resetState(t)
+	ch, _, _, shieldID := outfitFighter(t)
+
+	err := Transact(&State, func() error {
+		c, _ := FindChar(&State, ch.ID)
+		c.Gold = 25
+		inner := Transact(&State, func() error {
+			if err := MoveItemToCharacter(shieldID, ch.ID, &State); err != nil {
+				return err
+			}
+			return errors.New("injected failure")
+		})
+		if inner == nil {
+			t.Error("inner transaction should have failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _ := FindChar(&State, ch.ID)
+	if c.Gold != 25 {
+		t.Errorf("outer change was lost: gold %d, want 25", c.Gold)
+	}
+	if hasID(c.Items, shieldID) {
+		t.Error("inner change survived its rollback: shield is still carried")
+	}
+}
+
+func TestMoveItemsToCharacterFailsMidway(t *testing.T) {
+	resetState(t)
+	ch, _, _, shieldID := outfitFighter(t)
+	before := snapshotState(t)
+
+	if err := MoveItemsToCharacter([]int{shieldID, 999}, ch.ID, &State); err == nil {
+		t.Fatal("moving a missing item should fail")
+	}
+	assertUnchanged(t, before)
+}
+
+func TestRollbackKeepsPointersTakenBefore(t *testing.T) {
+	resetState(t)
+	ch, armorID, swordID, _ := outfitFighter(t)
+	c, _ := FindChar(&State, ch.ID)
+	armor := ArmorByID[armorID]
+
+	err := Transact(&State, func() error {
+		for i := range 3 {
+			AddCharacter(&State, fmt.Sprintf("Hireling %d", i))
+		}
+		armor.Bonus = 3
+		c.Gold = 500
+		if err := DeleteItem(swordID, &State); err != nil {
+			return err
+		}
+		return errors.New("injected failure")
+	})
+	if err == nil {
+		t.Fatal("Transact should return the injected failure")
+	}
+
+	c.Gold = 7
+	if found, _ := FindChar(&State, ch.ID); found.Gold != 7 {
+		t.Errorf("a character pointer taken before the transaction no longer reaches the party")
+	}
+	armor.Bonus = 1
+	if ArmorByID[armorID].Bonus != 1 || ArmorByID[armorID] != armor {
+		t.Errorf("an armor pointer taken before the transaction no longer reaches the registry")
+	}
+	if _, err := FindItemByID(swordID); err != nil {
+		t.Errorf("the deleted sword was not put back: %v", err)
+	}
+}
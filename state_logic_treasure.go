@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TREASURE
+
+// TreasureShare reports what one character took from a divided treasure.
+type TreasureShare struct {
+	CharacterID int
+	Name        string
+	Gold        int
+	Items       []int
+}
+
+// DivideTreasure shares out a haul. Gold is split evenly between the living characters, with any
+// remainder going one coin each to the first in the party, and each item goes to the character
+// it is assigned to (character ID to item IDs). Either all of it is handed out or none of it is.
+func DivideTreasure(p *Party, gold int, items map[int][]int) ([]TreasureShare, error) {
+	if gold < 0 {
+		return nil, fmt.Errorf("gold cannot be negative")
+	}
+	var shares []TreasureShare
+	err := Transact(p, func() error {
+		var living []*Character
+		for i := range p.Characters {
+			if !IsDead(&p.Characters[i]) {
+				living = append(living, &p.Characters[i])
+			}
+		}
+		if len(living) == 0 {
+			return fmt.Errorf("no one in the party can take a share")
+		}
+
+		shares = make([]TreasureShare, 0, len(living))
+		for i, ch := range living {
+			share := gold / len(living)
+			if i < gold%len(living) {
+				share++
+			}
+			ch.Gold += share
+			emit(EventTreasure, []int{ch.ID}, nil, "%s took %d gold as their share", ch.Name, share)
+			shares = append(shares, TreasureShare{CharacterID: ch.ID, Name: ch.Name, Gold: share, Items: []int{}})
+		}
+
+		charIDs := make([]int, 0, len(items))
+		for id := range items {
+			charIDs = append(charIDs, id)
+		}
+		sort.Ints(charIDs)
+		for _, charID := range charIDs {
+			ch, err := FindChar(p, charID)
+			if err != nil {
+				return err
+			}
+			if IsDead(ch) {
+				return fmt.Errorf("%s is dead and cannot take treasure", ch.Name)
+			}
+			for _, itemID := range items[charID] {
+				if err := moveItemToCharacter(itemID, charID, p); err != nil {
+					return fmt.Errorf("%s cannot take item %d: %w", ch.Name, itemID, err)
+				}
+			}
+			for i := range shares {
+				if shares[i].CharacterID == charID {
+					shares[i].Items = append(shares[i].Items, items[charID]...)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// resetState starts a test with a single empty party and a journal of its own.
+func resetState(t *testing.T) {
+	t.Helper()
+	JournalPath = filepath.Join(t.TempDir(), "events.jsonl")
+	Campaigns = nil
+	nextCampaignID = 1
+	nextEventSeq = 1
+	pendingEvents = nil
+	sinceSnapshot = map[*PartyRecord]int{}
+	journalIndex = nil
+	activeParty = nil
+	State = Party{Characters: []Character{}}
+	loadRegistry(newPartyRegistry())
+	InitCampaigns()
+}
+
+// apiResponse sends a JSON request through the API routes and returns the recorded response.
+func apiResponse(e *echo.Echo, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+// apiRequest sends a JSON request through the API routes and fails the test on an error status.
+func apiRequest(t *testing.T, e *echo.Echo, method, path, body string) {
+	t.Helper()
+	if rec := apiResponse(e, method, path, body); rec.Code >= 400 {
+		t.Fatalf("%s %s: %d %s", method, path, rec.Code, rec.Body)
+	}
+}
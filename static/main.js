@@ -0,0 +1,235 @@
+// Dungeon Party pages are rendered on the server and work without JavaScript. When it is
+// available, each page follows the party's event stream and refreshes itself as things change.
+(function () {
+    "use strict";
+
+    const page = document.getElementById("page");
+    if (!page) {
+        return;
+    }
+
+    // refresh fetches the current page again and swaps in its content, then lets anything
+    // enhancing the page know with a "party:refreshed" event.
+    async function refresh() {
+        const response = await fetch(window.location.href, {headers: {Accept: "text/html"}});
+        if (!response.ok) {
+            return;
+        }
+        const next = new DOMParser().parseFromString(await response.text(), "text/html");
+        const nextPage = next.getElementById("page");
+        const meta = document.querySelector("header .meta");
+        const nextMeta = next.querySelector("header .meta");
+        if (nextPage) {
+            page.innerHTML = nextPage.innerHTML;
+        }
+        if (meta && nextMeta) {
+            meta.innerHTML = nextMeta.innerHTML;
+        }
+        document.dispatchEvent(new CustomEvent("party:refreshed"));
+    }
+
+    // Changes often come in bursts (one request can journal several events), so wait for a
+    // quiet moment before refreshing.
+    let pending = null;
+    function scheduleRefresh() {
+        clearTimeout(pending);
+        pending = setTimeout(refresh, 150);
+    }
+
+    function follow() {
+        if (!window.EventSource) {
+            return;
+        }
+        let url = "/api/events/stream";
+        const character = document.body.dataset.character;
+        if (character) {
+            url += "?character=" + encodeURIComponent(character);
+        }
+        // EventSource reconnects on its own, sending the last event it saw so nothing is missed
+        const stream = new EventSource(url);
+        stream.onmessage = scheduleRefresh;
+        const types = [
+            "character-added", "character-changed", "character-removed", "status-changed",
+            "item-moved", "item-equipped", "item-used", "item-destroyed",
+            "spell-memorized", "spell-cast", "spell-learned", "effect", "encounter",
+            "experience", "treasure", "retainer", "rest", "clock", "history", "session", "changed",
+        ];
+        for (const type of types) {
+            stream.addEventListener(type, scheduleRefresh);
+        }
+    }
+
+    window.DungeonParty = {refresh: refresh};
+    follow();
+})();
+
+// The inventory board. Items can be dragged between a character, the party, storage and limbo,
+// or moved with the "Move to" menu beside each item, which also works on touch screens.
+// Each move is made through the move endpoint; if it is refused (say, because the character's
+// inventory is full) the item goes back and the reason is shown in the column it was dropped on.
+(function () {
+    "use strict";
+
+    const page = document.getElementById("page");
+    if (!page) {
+        return;
+    }
+
+    function board() {
+        return page.querySelector(".board");
+    }
+
+    function locations() {
+        return Array.from(page.querySelectorAll(".location"));
+    }
+
+    // destination describes where a column sends items, as the move endpoint expects it.
+    function destination(column) {
+        const character = column.dataset.character;
+        if (character) {
+            return {Location: "character", CharacterID: Number(character)};
+        }
+        return {Location: column.dataset.location, CharacterID: 0};
+    }
+
+    function columnTitle(column) {
+        const heading = column.querySelector("h2");
+        return heading ? heading.textContent.trim() : column.dataset.location;
+    }
+
+    function showError(column, message) {
+        clearErrors();
+        const error = document.createElement("p");
+        error.className = "error";
+        error.setAttribute("role", "alert");
+        error.textContent = message;
+        column.querySelector("h2").after(error);
+    }
+
+    function clearErrors() {
+        for (const error of page.querySelectorAll(".board .error")) {
+            error.remove();
+        }
+    }
+
+    // moveItem moves an item into a column. The item is shown there at once and put back if
+    // the server refuses the move.
+    async function moveItem(itemID, column) {
+        const item = page.querySelector(`.item[data-item="${itemID}"]`);
+        const list = column.querySelector("ul");
+        if (!item || !list || item.parentElement === list) {
+            return;
+        }
+        const from = item.parentElement;
+        const next = item.nextSibling;
+        clearErrors();
+        list.querySelector(".empty")?.remove();
+        list.append(item);
+        item.classList.add("moving");
+
+        let message = null;
+        try {
+            const response = await fetch(`/api/items/${itemID}/move`, {
+                method: "POST",
+                headers: {"Content-Type": "application/json"},
+                body: JSON.stringify(destination(column)),
+            });
+            if (!response.ok) {
+                const body = await response.json().catch(() => ({}));
+                message = body.Error || body.message || `The move failed (${response.status})`;
+            }
+        } catch (err) {
+            message = "The server could not be reached";
+        }
+        item.classList.remove("moving");
+
+        if (message) {
+            from.insertBefore(item, next);
+            const menu = item.querySelector(".move-to");
+            if (menu) {
+                menu.value = "";
+            }
+            showError(column, `${item.firstChild.textContent.trim()}: ${message}`);
+            return;
+        }
+        window.DungeonParty?.refresh();
+    }
+
+    // enhance makes the items draggable and gives each one a "Move to" menu. It runs again
+    // whenever the page is refreshed.
+    function enhance() {
+        if (!board()) {
+            return;
+        }
+        const columns = locations();
+        for (const item of page.querySelectorAll(".item")) {
+            item.draggable = true;
+            const here = item.closest(".location");
+            const menu = document.createElement("select");
+            menu.className = "move-to";
+            menu.setAttribute("aria-label", "Move to");
+            menu.append(new Option("Move to…", ""));
+            columns.forEach((column, i) => {
+                if (column !== here) {
+                    menu.append(new Option(columnTitle(column), String(i)));
+                }
+            });
+            menu.addEventListener("change", () => {
+                if (menu.value !== "") {
+                    moveItem(item.dataset.item, columns[Number(menu.value)]);
+                }
+            });
+            item.append(" ", menu);
+        }
+    }
+
+    page.addEventListener("dragstart", (event) => {
+        const item = event.target.closest?.(".item");
+        if (!item) {
+            return;
+        }
+        event.dataTransfer.setData("text/plain", item.dataset.item);
+        event.dataTransfer.effectAllowed = "move";
+        item.classList.add("dragging");
+    });
+
+    page.addEventListener("dragend", (event) => {
+        event.target.closest?.(".item")?.classList.remove("dragging");
+        for (const column of locations()) {
+            column.classList.remove("drop-target");
+        }
+    });
+
+    page.addEventListener("dragover", (event) => {
+        const column = event.target.closest?.(".location");
+        if (!column) {
+            return;
+        }
+        event.preventDefault();
+        event.dataTransfer.dropEffect = "move";
+        column.classList.add("drop-target");
+    });
+
+    page.addEventListener("dragleave", (event) => {
+        const column = event.target.closest?.(".location");
+        if (column && !column.contains(event.relatedTarget)) {
+            column.classList.remove("drop-target");
+        }
+    });
+
+    page.addEventListener("drop", (event) => {
+        const column = event.target.closest?.(".location");
+        if (!column) {
+            return;
+        }
+        event.preventDefault();
+        column.classList.remove("drop-target");
+        const itemID = event.dataTransfer.getData("text/plain");
+        if (itemID) {
+            moveItem(itemID, column);
+        }
+    });
+
+    document.addEventListener("party:refreshed", enhance);
+    enhance();
+})();